@@ -0,0 +1,126 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultAnnotations lists the markers counted when no others are given
+var DefaultAnnotations = []string{"TODO", "FIXME", "HACK", "XXX", "BUG"}
+
+// Annotation is a marker such as TODO found in a comment, with the text of
+// the comment from the marker on
+type Annotation struct {
+	Path   string
+	Line   int
+	Marker string
+	Text   string
+}
+
+// startComment separates the text of a comment from that of an earlier one
+// on the same line
+func (c *lineCounter) startComment() {
+	if len(c.comment) > 0 {
+		c.addComment("\n")
+	}
+}
+
+// addComment collects comment text of the current line, up to the size of a
+// line head, to be searched for annotations at the end of the line
+func (c *lineCounter) addComment(text string) {
+	if len(c.lines.annotations) == 0 {
+		return
+	}
+	if room := lineHeadSize - len(c.comment); len(text) > room {
+		text = text[:max(room, 0)]
+	}
+	c.comment = append(c.comment, text...)
+}
+
+// trackAnnotations counts the annotation markers in the comment text of the
+// current line. A marker only counts as a whole word, so TODOS and XXXL are
+// not annotations, and its text runs to the end of its comment.
+func (c *lineCounter) trackAnnotations() {
+	text := string(c.comment)
+	for _, marker := range c.lines.annotations {
+		for i := 0; ; {
+			j := strings.Index(text[i:], marker)
+			if j < 0 {
+				break
+			}
+			start, end := i+j, i+j+len(marker)
+			i = end
+			if start > 0 && isIdentChar(text[start-1]) || end < len(text) && isIdentChar(text[end]) {
+				continue
+			}
+
+			if c.stats.Annotations == nil {
+				c.stats.Annotations = make(map[string]int)
+			}
+			c.stats.Annotations[marker]++
+			if c.lines.annotationList {
+				c.stats.AnnotationList = append(c.stats.AnnotationList, Annotation{
+					Path:   c.stats.FilePath,
+					Line:   c.lines.line,
+					Marker: marker,
+					Text:   strings.TrimSpace(commentText(text[start:])),
+				})
+			}
+		}
+	}
+}
+
+// commentText returns text up to the end of the comment it is in
+func commentText(text string) string {
+	if end := strings.IndexByte(text, '\n'); end >= 0 {
+		return text[:end]
+	}
+	return text
+}
+
+// addAnnotations adds annotation counts to the statistics of a language
+func (ls *LanguageStats) addAnnotations(counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	if ls.Annotations == nil {
+		ls.Annotations = make(map[string]int, len(counts))
+	}
+	for marker, n := range counts {
+		ls.Annotations[marker] += n
+	}
+}
+
+// ListAnnotations returns the annotations found in files and their embedded
+// regions, ordered by path and line
+func ListAnnotations(fileStats []*FileStats) []Annotation {
+	var annotations []Annotation
+	for _, fs := range fileStats {
+		if fs == nil {
+			continue
+		}
+		annotations = append(annotations, fs.AnnotationList...)
+		for _, child := range fs.Embedded {
+			annotations = append(annotations, child.AnnotationList...)
+		}
+	}
+
+	sort.SliceStable(annotations, func(i, j int) bool {
+		if annotations[i].Path != annotations[j].Path {
+			return annotations[i].Path < annotations[j].Path
+		}
+		return annotations[i].Line < annotations[j].Line
+	})
+	return annotations
+}
+
+// sortedMarkers returns the markers of annotation counts in alphabetical
+// order
+func sortedMarkers(counts map[string]int) []string {
+	markers := make([]string, 0, len(counts))
+	for marker := range counts {
+		markers = append(markers, marker)
+	}
+	sort.Strings(markers)
+	return markers
+}
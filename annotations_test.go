@@ -0,0 +1,154 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCountLinesAnnotations(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name         string
+		filename     string
+		content      string
+		markers      []string
+		want         map[string]int
+		wantList     []Annotation
+		wantEmbedded map[string]int
+	}{
+		{
+			name:     "Markers in comments, not strings",
+			filename: "main.go",
+			content:  "// TODO(ann): handle errors\ns := \"TODO not a comment\"\n/* FIXME: leak */ x := 1 // XXX and TODOS\n/*\n * HACK around\n */\n",
+			markers:  DefaultAnnotations,
+			want:     map[string]int{"TODO": 1, "FIXME": 1, "XXX": 1, "HACK": 1},
+			wantList: []Annotation{
+				{Line: 1, Marker: "TODO", Text: "TODO(ann): handle errors"},
+				{Line: 3, Marker: "FIXME", Text: "FIXME: leak"},
+				{Line: 3, Marker: "XXX", Text: "XXX and TODOS"},
+				{Line: 5, Marker: "HACK", Text: "HACK around"},
+			},
+		},
+		{
+			name:     "Docstrings are comments",
+			filename: "app.py",
+			content:  "def f():\n    \"\"\"TODO: document\"\"\"\n    return \"BUG\"  # BUG: off by one\n",
+			markers:  DefaultAnnotations,
+			want:     map[string]int{"TODO": 1, "BUG": 1},
+			wantList: []Annotation{
+				{Line: 2, Marker: "TODO", Text: "TODO: document"},
+				{Line: 3, Marker: "BUG", Text: "BUG: off by one"},
+			},
+		},
+		{
+			name:     "Custom markers",
+			filename: "custom.go",
+			content:  "// NOTE: keep in sync\n// TODO: not counted\n// NOTE twice NOTE\n",
+			markers:  []string{"NOTE"},
+			want:     map[string]int{"NOTE": 3},
+			wantList: []Annotation{
+				{Line: 1, Marker: "NOTE", Text: "NOTE: keep in sync"},
+				{Line: 3, Marker: "NOTE", Text: "NOTE twice NOTE"},
+				{Line: 3, Marker: "NOTE", Text: "NOTE"},
+			},
+		},
+		{
+			name:         "Embedded regions",
+			filename:     "App.vue",
+			content:      "<!-- TODO: layout -->\n<template><div/></template>\n<script>\n// FIXME: state\n</script>\n",
+			markers:      DefaultAnnotations,
+			want:         map[string]int{"TODO": 1},
+			wantList:     []Annotation{{Line: 1, Marker: "TODO", Text: "TODO: layout"}},
+			wantEmbedded: map[string]int{"FIXME": 1},
+		},
+		{
+			name:     "Not counted by default",
+			filename: "off.go",
+			content:  "// TODO: later\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			opts := CountOptions{Annotations: tt.markers, AnnotationList: true}
+			stats, err := CountLinesWithOptions(filePath, Languages[filepath.Ext(tt.filename)], opts)
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(stats.Annotations, tt.want) {
+				t.Errorf("Annotations = %v, want %v", stats.Annotations, tt.want)
+			}
+			for i := range tt.wantList {
+				tt.wantList[i].Path = filePath
+			}
+			if !reflect.DeepEqual(stats.AnnotationList, tt.wantList) {
+				t.Errorf("AnnotationList = %v, want %v", stats.AnnotationList, tt.wantList)
+			}
+			var embedded map[string]int
+			for _, child := range stats.Embedded {
+				if child.Annotations != nil {
+					embedded = child.Annotations
+				}
+			}
+			if !reflect.DeepEqual(embedded, tt.wantEmbedded) {
+				t.Errorf("embedded Annotations = %v, want %v", embedded, tt.wantEmbedded)
+			}
+		})
+	}
+}
+
+func TestListAnnotations(t *testing.T) {
+	fileStats := []*FileStats{
+		{
+			FilePath:       "b.go",
+			AnnotationList: []Annotation{{Path: "b.go", Line: 4, Marker: "TODO"}},
+		},
+		{
+			FilePath:       "a.vue",
+			AnnotationList: []Annotation{{Path: "a.vue", Line: 9, Marker: "HACK"}},
+			Embedded: []*FileStats{{
+				FilePath:       "a.vue",
+				AnnotationList: []Annotation{{Path: "a.vue", Line: 2, Marker: "FIXME"}},
+			}},
+		},
+		nil,
+	}
+
+	want := []Annotation{
+		{Path: "a.vue", Line: 2, Marker: "FIXME"},
+		{Path: "a.vue", Line: 9, Marker: "HACK"},
+		{Path: "b.go", Line: 4, Marker: "TODO"},
+	}
+	if got := ListAnnotations(fileStats); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListAnnotations() = %v, want %v", got, want)
+	}
+}
+
+func TestAggregateAnnotations(t *testing.T) {
+	fileStats := []*FileStats{
+		{Language: "Go", Annotations: map[string]int{"TODO": 2}},
+		{Language: "Go", Annotations: map[string]int{"TODO": 1, "FIXME": 1}},
+		{Language: "Python", Annotations: map[string]int{"BUG": 1}},
+	}
+
+	langStats := AggregateStats(fileStats)
+	if got, want := langStats["Go"].Annotations, map[string]int{"TODO": 3, "FIXME": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Go Annotations = %v, want %v", got, want)
+	}
+	if got, want := TotalStats(langStats).Annotations, map[string]int{"TODO": 3, "FIXME": 1, "BUG": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Total Annotations = %v, want %v", got, want)
+	}
+}
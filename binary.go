@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// binaryProbeSize is how much of a file is inspected to tell binary content
+// from text
+const binaryProbeSize = 8 * 1024
+
+// maxInvalidUTF8 is the share of bytes that may fail to decode as UTF-8
+// before a file is taken for binary. Some is allowed for Latin-1 text.
+const maxInvalidUTF8 = 0.3
+
+// Reasons recorded for skipped files
+const (
+	SkipExcludedPattern = "excluded by pattern"
+	SkipHidden          = "hidden file"
+	SkipBinaryExtension = "binary extension"
+	SkipBinaryNUL       = "binary content (NUL bytes)"
+	SkipBinaryUTF8      = "binary content (invalid UTF-8)"
+	SkipUnsupported     = "unsupported file type"
+)
+
+// SkippedFile records a file that was not counted and why
+type SkippedFile struct {
+	Path   string
+	Reason string
+}
+
+// SniffBinary inspects the first block of a file and returns the reason it
+// holds binary content, or "" for text. UTF-16 text is recognised first so
+// that its zero bytes are not mistaken for binary.
+func SniffBinary(path string) string {
+	return sniffBinary(readHead(path, binaryProbeSize))
+}
+
+// sniffBinary is SniffBinary for a block already read
+func sniffBinary(head []byte) string {
+	if bytes.HasPrefix(head, []byte{0xFF, 0xFE}) || bytes.HasPrefix(head, []byte{0xFE, 0xFF}) || guessUTF16(head) != "" {
+		return ""
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return SkipBinaryNUL
+	}
+
+	// A character cut off at the end of the block is not counted as invalid
+	invalid := 0
+	for i := 0; i < len(head); {
+		r, size := utf8.DecodeRune(head[i:])
+		if r == utf8.RuneError && size == 1 && len(head)-i >= utf8.UTFMax {
+			invalid++
+		}
+		i += size
+	}
+	if float64(invalid) > maxInvalidUTF8*float64(len(head)) {
+		return SkipBinaryUTF8
+	}
+	return ""
+}
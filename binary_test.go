@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestSniffBinary(t *testing.T) {
+	latin1 := bytes.Repeat([]byte("caf\xe9 cr\xe8me "), 50)
+	invalid := bytes.Repeat([]byte{0x89, 0xC3, 0x28, 0xA0, 'a'}, 100)
+	// A multi-byte character split by the end of the probe block
+	truncated := append(bytes.Repeat([]byte("a"), binaryProbeSize-1), 0xE2)
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{"Source code", []byte("package main\n\nfunc main() {}\n"), ""},
+		{"UTF-8 text", []byte("// héllo wörld 😀\n"), ""},
+		{"UTF-16LE text", encodeUTF16("int x = 1;\n", binary.LittleEndian, true), ""},
+		{"UTF-16LE without BOM", encodeUTF16("int x = 1;\n", binary.LittleEndian, false), ""},
+		{"Latin-1 text", latin1, ""},
+		{"Truncated character", truncated, ""},
+		{"NUL bytes", []byte("ELF\x00\x01\x02\x00\x00header"), SkipBinaryNUL},
+		{"Invalid UTF-8", invalid, SkipBinaryUTF8},
+		{"Empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sniffBinary(tt.content); got != tt.want {
+				t.Errorf("sniffBinary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import "sort"
+
+// DataLanguages lists the languages left out of the breakdown, since GitHub's
+// language bar only counts programming and markup languages. These are the
+// ones linguist classifies as data or prose, plus configuration files.
+var DataLanguages = map[string]bool{
+	"Apache Config":     true,
+	"Authors":           true,
+	"Babel Config":      true,
+	"CSV":               true,
+	"Changelog":         true,
+	"Composer":          true,
+	"Contributors":      true,
+	"Docker Config":     true,
+	"ESLint Config":     true,
+	"EditorConfig":      true,
+	"Environment":       true,
+	"Git Config":        true,
+	"GraphQL":           true,
+	"INI":               true,
+	"JSON":              true,
+	"JSON5":             true,
+	"License":           true,
+	"Markdown":          true,
+	"Maven POM":         true,
+	"NPM Config":        true,
+	"NPM Package":       true,
+	"Prettier Config":   true,
+	"Procfile":          true,
+	"Protocol Buffers":  true,
+	"Qt Translation":    true,
+	"Readme":            true,
+	"SQL":               true,
+	"TOML":              true,
+	"TSV":               true,
+	"Text":              true,
+	"Travis CI":         true,
+	"TypeScript Config": true,
+	"Unknown":           true,
+	"XML":               true,
+	"YAML":              true,
+	"Yarn Config":       true,
+}
+
+// LanguageShare is the part of a code base written in one language
+type LanguageShare struct {
+	Language string
+	Bytes    int
+	Percent  float64
+}
+
+// Breakdown returns each language's share of the total size in bytes, the
+// way GitHub's language bar reports it, largest first. Data and prose
+// languages are left out of both the shares and the total.
+func Breakdown(langStats map[string]*LanguageStats) []LanguageShare {
+	var shares []LanguageShare
+	total := 0
+	for _, stats := range langStats {
+		if DataLanguages[stats.Language] || stats.Bytes == 0 {
+			continue
+		}
+		shares = append(shares, LanguageShare{Language: stats.Language, Bytes: stats.Bytes})
+		total += stats.Bytes
+	}
+
+	for i := range shares {
+		shares[i].Percent = float64(shares[i].Bytes) * 100 / float64(total)
+	}
+
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Bytes != shares[j].Bytes {
+			return shares[i].Bytes > shares[j].Bytes
+		}
+		return shares[i].Language < shares[j].Language
+	})
+	return shares
+}
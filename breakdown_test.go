@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCountLinesSize(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name           string
+		content        []byte
+		wantBytes      int
+		wantCharacters int
+	}{
+		{
+			name:           "ASCII with LF",
+			content:        []byte("x := 1\n// done\n"),
+			wantBytes:      15,
+			wantCharacters: 15,
+		},
+		{
+			name:           "Multi-byte characters and CRLF",
+			content:        []byte("s := \"héllo 世界\"\r\n"),
+			wantBytes:      22,
+			wantCharacters: 17,
+		},
+		{
+			name:           "UTF-16 with a byte order mark",
+			content:        encodeUTF16("x := 1\n", binary.LittleEndian, true),
+			wantBytes:      16,
+			wantCharacters: 7,
+		},
+		{
+			name:           "Characters split across segments",
+			content:        []byte("// " + strings.Repeat("é", lineHeadSize) + "\n"),
+			wantBytes:      3 + 2*lineHeadSize + 1,
+			wantCharacters: 3 + lineHeadSize + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, "size.go")
+			if err := os.WriteFile(filePath, tt.content, 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, Languages[".go"])
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			if stats.Bytes != tt.wantBytes {
+				t.Errorf("Bytes = %d, want %d", stats.Bytes, tt.wantBytes)
+			}
+			if stats.Characters != tt.wantCharacters {
+				t.Errorf("Characters = %d, want %d", stats.Characters, tt.wantCharacters)
+			}
+		})
+	}
+}
+
+func TestBreakdown(t *testing.T) {
+	langStats := map[string]*LanguageStats{
+		"Go":       {Language: "Go", Bytes: 600},
+		"Shell":    {Language: "Shell", Bytes: 200},
+		"Python":   {Language: "Python", Bytes: 200},
+		"Markdown": {Language: "Markdown", Bytes: 5000},
+		"JSON":     {Language: "JSON", Bytes: 900},
+		"Empty":    {Language: "Empty"},
+	}
+
+	want := []LanguageShare{
+		{Language: "Go", Bytes: 600, Percent: 60},
+		{Language: "Python", Bytes: 200, Percent: 20},
+		{Language: "Shell", Bytes: 200, Percent: 20},
+	}
+	if got := Breakdown(langStats); !reflect.DeepEqual(got, want) {
+		t.Errorf("Breakdown() = %v, want %v", got, want)
+	}
+
+	if got := Breakdown(map[string]*LanguageStats{"JSON": {Language: "JSON", Bytes: 10}}); len(got) != 0 {
+		t.Errorf("Breakdown() of data only = %v, want none", got)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Defaults for the cost estimate, the same as scc's
+const (
+	DefaultProjectType = "organic"
+	DefaultWage        = 56286
+	DefaultOverhead    = 2.4
+)
+
+// ProjectType holds the basic COCOMO coefficients for a class of project
+type ProjectType struct {
+	A, B float64 // effort = A * KLOC^B person-months
+	C, D float64 // schedule = C * effort^D months
+}
+
+// ProjectTypes maps each basic COCOMO project class to its coefficients.
+// Organic projects are small teams on familiar problems, embedded ones are
+// built under tight hardware or operational constraints, and semi-detached
+// ones fall in between.
+var ProjectTypes = map[string]ProjectType{
+	"organic":       {A: 2.4, B: 1.05, C: 2.5, D: 0.38},
+	"semi-detached": {A: 3.0, B: 1.12, C: 2.5, D: 0.35},
+	"embedded":      {A: 3.6, B: 1.20, C: 2.5, D: 0.32},
+}
+
+// CostOptions configures the cost estimate
+type CostOptions struct {
+	ProjectType string
+	Wage        float64 // average annual salary of a developer
+	Overhead    float64 // multiplier covering costs other than salaries
+}
+
+// Estimate is a basic COCOMO estimate of the effort and cost to develop a
+// code base
+type Estimate struct {
+	ProjectType string
+	Effort      float64 // person-months
+	Schedule    float64 // months
+	People      float64 // average team size
+	Cost        float64 // in the currency of the wage
+}
+
+// ValidateCostOptions checks that the project type is known and that the wage
+// and overhead are usable
+func ValidateCostOptions(options CostOptions) error {
+	if _, ok := ProjectTypes[options.ProjectType]; !ok {
+		types := make([]string, 0, len(ProjectTypes))
+		for name := range ProjectTypes {
+			types = append(types, name)
+		}
+		sort.Strings(types)
+		return fmt.Errorf("unknown project type %q (want one of %s)", options.ProjectType, strings.Join(types, ", "))
+	}
+	if options.Wage < 0 {
+		return fmt.Errorf("wage must not be negative, got %v", options.Wage)
+	}
+	if options.Overhead <= 0 {
+		return fmt.Errorf("overhead must be positive, got %v", options.Overhead)
+	}
+	return nil
+}
+
+// EstimateCost estimates the effort, schedule, team size and cost to develop
+// the code lines of stats with basic COCOMO
+func EstimateCost(stats *LanguageStats, options CostOptions) *Estimate {
+	project := ProjectTypes[options.ProjectType]
+	estimate := &Estimate{ProjectType: options.ProjectType}
+	if stats.CodeLines == 0 {
+		return estimate
+	}
+
+	kloc := float64(stats.CodeLines) / 1000
+	estimate.Effort = project.A * math.Pow(kloc, project.B)
+	estimate.Schedule = project.C * math.Pow(estimate.Effort, project.D)
+	estimate.People = estimate.Effort / estimate.Schedule
+	estimate.Cost = estimate.Effort * options.Wage / 12 * options.Overhead
+	return estimate
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateCost(t *testing.T) {
+	tests := []struct {
+		name         string
+		codeLines    int
+		options      CostOptions
+		wantEffort   float64
+		wantSchedule float64
+		wantPeople   float64
+		wantCost     float64
+	}{
+		{
+			name:         "Organic",
+			codeLines:    10000,
+			options:      CostOptions{ProjectType: "organic", Wage: DefaultWage, Overhead: DefaultOverhead},
+			wantEffort:   26.9284,
+			wantSchedule: 8.7382,
+			wantPeople:   3.0817,
+			wantCost:     303138.87,
+		},
+		{
+			name:         "Embedded",
+			codeLines:    10000,
+			options:      CostOptions{ProjectType: "embedded", Wage: DefaultWage, Overhead: DefaultOverhead},
+			wantEffort:   57.0562,
+			wantSchedule: 9.1192,
+			wantPeople:   6.2567,
+			wantCost:     642292.55,
+		},
+		{
+			name:         "Wage and overhead scale the cost",
+			codeLines:    10000,
+			options:      CostOptions{ProjectType: "organic", Wage: 120000, Overhead: 1},
+			wantEffort:   26.9284,
+			wantSchedule: 8.7382,
+			wantPeople:   3.0817,
+			wantCost:     269284.43,
+		},
+		{
+			name:    "No code",
+			options: CostOptions{ProjectType: "organic", Wage: DefaultWage, Overhead: DefaultOverhead},
+		},
+	}
+
+	near := func(got, want, tolerance float64) bool {
+		return math.Abs(got-want) <= tolerance
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateCost(&LanguageStats{CodeLines: tt.codeLines}, tt.options)
+			if got.ProjectType != tt.options.ProjectType {
+				t.Errorf("ProjectType = %q, want %q", got.ProjectType, tt.options.ProjectType)
+			}
+			if !near(got.Effort, tt.wantEffort, 0.001) {
+				t.Errorf("Effort = %v, want %v", got.Effort, tt.wantEffort)
+			}
+			if !near(got.Schedule, tt.wantSchedule, 0.001) {
+				t.Errorf("Schedule = %v, want %v", got.Schedule, tt.wantSchedule)
+			}
+			if !near(got.People, tt.wantPeople, 0.001) {
+				t.Errorf("People = %v, want %v", got.People, tt.wantPeople)
+			}
+			if !near(got.Cost, tt.wantCost, 0.01) {
+				t.Errorf("Cost = %v, want %v", got.Cost, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestValidateCostOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options CostOptions
+		wantErr bool
+	}{
+		{"Defaults", CostOptions{ProjectType: DefaultProjectType, Wage: DefaultWage, Overhead: DefaultOverhead}, false},
+		{"Semi-detached", CostOptions{ProjectType: "semi-detached", Wage: 0, Overhead: 1}, false},
+		{"Unknown project type", CostOptions{ProjectType: "agile", Wage: DefaultWage, Overhead: DefaultOverhead}, true},
+		{"Negative wage", CostOptions{ProjectType: "organic", Wage: -1, Overhead: DefaultOverhead}, true},
+		{"No overhead", CostOptions{ProjectType: "organic", Wage: DefaultWage}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCostOptions(tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCostOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,8 +1,9 @@
 package main
 
 import (
-	"bufio"
+	"io"
 	"os"
+	"sort"
 	"strings"
 )
 
@@ -15,16 +16,117 @@ type FileStats struct {
 	CommentLines int
 	CodeLines    int
 	TotalLines   int
+
+	// Encoding is the detected text encoding, such as "UTF-8" or "UTF-16LE"
+	Encoding string
+
+	// LineEndings is the line terminator style: "LF", "CRLF", "CR", "Mixed",
+	// or empty when the file has a single unterminated line
+	LineEndings string
+
+	// Generated is set for files written by tools, recognised by a marker
+	// such as "Code generated ... DO NOT EDIT." or by their name
+	Generated bool
+
+	// Minified is set for files whose long, dense lines show they were
+	// compressed for distribution
+	Minified bool
+
+	// LogicalLines counts statements, as described by the language's
+	// Statements rules, so that code is measured the same way however it is
+	// formatted
+	LogicalLines int
+
+	// Bytes is the size of the file on disk, and Characters the number of
+	// characters in its decoded text, including line terminators
+	Bytes      int
+	Characters int
+
+	// UniqueLines counts the distinct code lines, ignoring the whitespace
+	// around them. ContentHash identifies the file's content, ignoring line
+	// endings and the whitespace around each line.
+	UniqueLines int
+	ContentHash uint64
+	lineHashes  map[uint64]struct{}
+
+	// MixedLines counts code lines that also hold a comment, such as a
+	// trailing comment on a struct field. They are included in CodeLines.
+	MixedLines int
+
+	// DocLines counts comment lines that document an API, such as Rust's ///
+	// or a Python docstring. They are included in CommentLines.
+	DocLines int
+
+	// DisabledLines counts lines in #if 0 blocks and other branches the
+	// preprocessor always removes, when CountOptions.DisabledCode is set.
+	// They are included in CommentLines.
+	DisabledLines int
+
+	// Complexity estimates cyclomatic complexity as the number of branch
+	// keywords and operators in code, such as if and &&
+	Complexity int
+
+	// Functions, Types and Interfaces count the declarations matched by the
+	// language's Declarations patterns. FunctionLines counts the code lines
+	// from each function declaration to the next declaration.
+	Functions     int
+	Types         int
+	Interfaces    int
+	FunctionLines int
+
+	// Annotations counts markers such as TODO and FIXME in comments, by
+	// marker, when CountOptions.Annotations is set. AnnotationList holds
+	// each of them, when CountOptions.AnnotationList is set too.
+	Annotations    map[string]int
+	AnnotationList []Annotation
+
+	// Embedded holds the statistics of regions written in other languages,
+	// such as <script> blocks in a Vue file. Their lines are not included
+	// in the counts above.
+	Embedded []*FileStats
+
+	// Diagnostics lists constructs that may have made the counts inaccurate
+	Diagnostics []Diagnostic
+}
+
+// Diagnostic describes a problem found at a line of a file while counting it
+type Diagnostic struct {
+	Line    int
+	Message string
 }
 
 // LanguageStats holds aggregated statistics for a language
 type LanguageStats struct {
-	Language     string
-	FileCount    int
-	BlankLines   int
-	CommentLines int
-	CodeLines    int
-	TotalLines   int
+	Language      string
+	FileCount     int
+	BlankLines    int
+	CommentLines  int
+	CodeLines     int
+	LogicalLines  int
+	TotalLines    int
+	MixedLines    int
+	DocLines      int
+	DisabledLines int
+	Complexity    int
+	Functions     int
+	Types         int
+	Interfaces    int
+	FunctionLines int
+	Bytes         int
+	Characters    int
+	UniqueLines   int
+	Annotations   map[string]int
+
+	lineHashes map[uint64]struct{}
+}
+
+// AverageFunctionLength returns the mean number of code lines per function,
+// or 0 if there are no functions
+func (ls *LanguageStats) AverageFunctionLength() float64 {
+	if ls.Functions == 0 {
+		return 0
+	}
+	return float64(ls.FunctionLines) / float64(ls.Functions)
 }
 
 // CountResult represents the result of counting a file
@@ -33,148 +135,607 @@ type CountResult struct {
 	Error error
 }
 
+// CountOptions enables optional counting modes
+type CountOptions struct {
+	// MarkdownCode counts fenced code blocks in Markdown under the language
+	// named by the fence instead of as Markdown
+	MarkdownCode bool
+
+	// DisabledCode counts the lines of #if 0 blocks, and the #else branch
+	// of #if 1, as comments in languages with a C preprocessor
+	DisabledCode bool
+
+	// Annotations lists markers, such as TODO, to count in comments, and
+	// AnnotationList also records where each of them was found
+	Annotations    []string
+	AnnotationList bool
+}
+
 // CountLines counts the lines in a file and categorizes them
 func CountLines(filePath string, lang *Language) (*FileStats, error) {
+	return CountLinesWithOptions(filePath, lang, CountOptions{})
+}
+
+// CountLinesWithOptions counts the lines in a file with the given counting
+// modes enabled
+func CountLinesWithOptions(filePath string, lang *Language, opts CountOptions) (*FileStats, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return countReader(filePath, file, info.Size(), lang, opts)
+}
+
+// countReader counts the lines of a file of the given size read from r
+func countReader(filePath string, r io.Reader, size int64, lang *Language, opts CountOptions) (*FileStats, error) {
 	stats := &FileStats{
 		FilePath:  filePath,
 		Language:  lang.Name,
 		Extension: "",
+		Bytes:     int(size),
 	}
 
-	scanner := bufio.NewScanner(file)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	inMultiLine := false
-	multiLineLevel := 0
-	inString := false
-	stringEnd := ""
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		stats.TotalLines++
-
-		lineHasCode := false
-		lineHasComment := false
-
-		for i := 0; i < len(line); {
-			if inString {
-				lineHasCode = true
-				if strings.HasPrefix(line[i:], stringEnd) {
-					// Check if escaped
-					escaped := false
-					if i > 0 && line[i-1] == '\\' {
-						bsCount := 0
-						for j := i - 1; j >= 0 && line[j] == '\\'; j-- {
-							bsCount++
-						}
-						if bsCount%2 == 1 {
-							escaped = true
-						}
-					}
-					if !escaped {
-						inString = false
-						i += len(stringEnd)
-					} else {
-						i++
-					}
-				} else {
-					i++
-				}
-				continue
-			}
+	text, encoding := decodeText(r)
+	stats.Encoding = encoding
+
+	lines := &lineTracker{
+		stats:          stats,
+		annotations:    opts.Annotations,
+		annotationList: opts.AnnotationList,
+	}
+	base := newLineCounter(lang, stats, lines)
+	base.disabledCode = opts.DisabledCode && lang.Preprocessor
+	var counter lineSink = base
+	if len(lang.EmbeddedRegions) > 0 || (opts.MarkdownCode && len(lang.CodeFences) > 0) {
+		counter = newRegionCounter(lang, stats, lines, opts)
+	}
+
+	generated := &generatedSink{lineSink: newHashSink(counter, stats)}
+	shape, err := countStream(text, generated, lines)
+	if err != nil {
+		return nil, err
+	}
+	stats.LineEndings = shape.lineEndings
+	stats.Characters = shape.characters
+	stats.Generated = generated.found || isGeneratedName(filePath)
+	stats.Minified = isMinified(filePath, shape, stats.TotalLines)
+
+	return stats, nil
+}
+
+// lineTracker numbers the physical lines of a file, so that counters for
+// embedded regions report diagnostics against the file as a whole
+type lineTracker struct {
+	stats *FileStats
+	line  int
+
+	// Markers to count in the comments of every region of the file
+	annotations    []string
+	annotationList bool
+}
+
+// report records a diagnostic for the given line of the file
+func (t *lineTracker) report(line int, message string) {
+	t.stats.Diagnostics = append(t.stats.Diagnostics, Diagnostic{Line: line, Message: message})
+}
+
+// lineCounter classifies the lines of a single language, carrying string and
+// comment state from one line to the next
+type lineCounter struct {
+	lang  *Language
+	stats *FileStats
+	lines *lineTracker
+
+	inMultiLine    bool
+	multiLineLevel int
+	block          BlockComment
+	blockIsDoc     bool
+	inString       bool
+	str            StringLiteral
+	stringEnd      string
+	stringLine     int
+
+	// A docstring may open the module and the body of each scope header
+	docStringPos  bool
+	inScopeHeader bool
+	bracketDepth  int
+
+	// Comment-only lines directly above the current line, which become
+	// documentation if it matches lang.DocCommentsBefore
+	pendingDoc int
+
+	// Preprocessor conditionals, tracked when disabledCode is set to count
+	// dead branches as comments
+	disabledCode bool
+	conditionals []conditional
+
+	// Whether the code lines being read belong to a function
+	inFunction bool
+
+	// Depth of the brackets open in the current statement, and the depths
+	// to return to at the end of each enclosing block or literal
+	statementDepth int
+	blockDepths    []int
+
+	// The code character and word before the next one, telling a block
+	// from a literal such as "= {1, 2}", and whether the last closing
+	// brace ended a literal
+	statementPrev byte
+	statementWord []byte
+	literalClosed bool
+
+	// Here-document bodies still to be read, in order, and those opened
+	// on the current line, which start on the next
+	heredocs    []heredocEnd
+	newHeredocs []heredocEnd
+
+	// State of the line being scanned. offset is the position in the line
+	// of the text passed to scan, and scanned how much of it is done.
+	head           string
+	offset         int
+	scanned        int
+	inHeredoc      bool
+	heredocEnd     int
+	lineInString   bool
+	lineDirective  bool
+	disabled       bool
+	lineHasCode    bool
+	lineHasComment bool
+	lineHasDoc     bool
+	lastCodeChar   byte
+	prevCodeChar   byte
+	escapedNewline bool
+	restIsComment  bool
+	shebang        bool
+
+	// Comment text of the current line, collected when annotations are
+	// counted
+	comment []byte
+}
+
+// newLineCounter creates a lineCounter that records into stats
+func newLineCounter(lang *Language, stats *FileStats, lines *lineTracker) *lineCounter {
+	return &lineCounter{
+		lang:         lang,
+		stats:        stats,
+		lines:        lines,
+		docStringPos: len(lang.DocStringDelimiters) > 0,
+	}
+}
+
+// finish reports a multi-line string literal or here-document that was
+// never closed
+func (c *lineCounter) finish() {
+	if c.inString {
+		c.lines.report(c.stringLine, "unterminated string literal")
+	}
+	for _, end := range c.heredocs {
+		c.lines.report(end.line, "unterminated here-document")
+	}
+}
+
+// beginLine resets the per-line state
+func (c *lineCounter) beginLine(head string) {
+	c.stats.TotalLines++
+	c.head = head
+	c.scanned = 0
+	c.lineInString = c.inString
+	c.lineHasCode = false
+	c.lineHasComment = false
+	c.lineHasDoc = false
+	c.lastCodeChar = 0
+	c.prevCodeChar = 0
+	c.statementWord = c.statementWord[:0]
+	c.escapedNewline = false
+	c.restIsComment = false
+	c.comment = c.comment[:0]
+
+	// A shebang is an interpreter directive, so it counts as code even
+	// in languages where # starts a comment
+	c.shebang = c.lines.line == 1 && strings.HasPrefix(head, "#!")
+
+	// A here-document body is string data up to its terminator line,
+	// which is scanned from the end of its delimiter
+	c.inHeredoc = len(c.heredocs) > 0
+	c.heredocEnd = 0
+	if c.inHeredoc {
+		if n, ok := matchHeredocEnd(head, c.heredocs[0]); ok {
+			c.heredocs = c.heredocs[1:]
+			c.heredocEnd = n
+		}
+	}
+
+	// Directives inside a comment or string do not count
+	c.lineDirective = false
+	if c.lang.Preprocessor && !c.inString && !c.inMultiLine {
+		_, _, c.lineDirective = directive(head)
+	}
+	c.disabled = false
+	if c.disabledCode && !c.inString && !c.inMultiLine {
+		c.disabled = c.trackConditional(head)
+	}
+
+	if !c.inScopeHeader && !c.inString && !c.inMultiLine && isScopeHeader(head, c.lang.DocStringScopes) {
+		c.inScopeHeader = true
+		c.bracketDepth = 0
+	}
+}
+
+// scan classifies the characters of the current line
+func (c *lineCounter) scan(line string, i int, final bool) int {
+	c.offset = c.scanned - i
+	n := c.scanText(line, i, final)
+	c.scanned = c.offset + n
+	return n
+}
+
+// scanText classifies line from offset i, stopping short of the end to keep
+// lookahead unless final is set
+func (c *lineCounter) scanText(line string, i int, final bool) int {
+	lang := c.lang
+	if c.shebang {
+		return len(line)
+	}
+	if c.restIsComment {
+		c.addComment(line[i:])
+		return len(line)
+	}
+	if c.inHeredoc && c.heredocEnd == 0 {
+		c.lineHasCode = c.lineHasCode || strings.TrimSpace(line[i:]) != ""
+		return len(line)
+	}
+	if end := c.heredocEnd - c.offset; i < end {
+		// Code such as PHP's "EOT;" may follow the delimiter
+		c.lineHasCode = true
+		i = min(end, len(line))
+	}
 
-			if inMultiLine {
-				lineHasComment = true
+	// Disabled code is not scanned, since it may be prose with stray quotes
+	if c.disabled {
+		c.lineHasComment = c.lineHasComment || strings.TrimSpace(line[i:]) != ""
+		return len(line)
+	}
+
+	for i < len(line) {
+		if !final && len(line)-i < maxLookahead {
+			break
+		}
 
-				// Check for nested multi-line start
-				if lang.NestedComments && lang.MultiLineStart != "" && strings.HasPrefix(line[i:], lang.MultiLineStart) {
-					multiLineLevel++
-					i += len(lang.MultiLineStart)
+		if c.inString {
+			c.lineHasCode = true
+			if c.str.Escape == EscapeBackslash && line[i] == '\\' {
+				c.escapedNewline = i == len(line)-1
+				i += 2
+				continue
+			}
+			if strings.HasPrefix(line[i:], c.stringEnd) {
+				i += len(c.stringEnd)
+				if c.str.Escape == EscapeDoubled && strings.HasPrefix(line[i:], c.stringEnd) {
+					i += len(c.stringEnd)
 					continue
 				}
+				c.inString = false
+				c.lastCodeChar = c.stringEnd[len(c.stringEnd)-1]
+			} else {
+				i++
+			}
+			continue
+		}
+
+		if c.inMultiLine {
+			c.lineHasComment = true
+			c.lineHasDoc = c.lineHasDoc || c.blockIsDoc
+
+			// Check for nested multi-line start
+			if lang.NestedComments && c.markerAt(line, i, c.block.Start) {
+				c.multiLineLevel++
+				i += len(c.block.Start)
+				continue
+			}
 
-				// Check for multi-line end
-				if lang.MultiLineEnd != "" && strings.HasPrefix(line[i:], lang.MultiLineEnd) {
-					if multiLineLevel > 0 {
-						multiLineLevel--
-						i += len(lang.MultiLineEnd)
-					} else {
-						inMultiLine = false
-						i += len(lang.MultiLineEnd)
-					}
+			// Check for multi-line end
+			if c.markerAt(line, i, c.block.End) {
+				if c.multiLineLevel > 0 {
+					c.multiLineLevel--
 				} else {
-					i++
+					c.inMultiLine = false
 				}
-				continue
+				i += len(c.block.End)
+			} else {
+				c.addComment(line[i : i+1])
+				i++
 			}
+			continue
+		}
 
-			// Not in string or multi-line comment
+		// Not in string or multi-line comment
+
+		// Check for multi-line comment start. Block markers are tried
+		// before line markers since some extend them, like Lua's --[[
+		if bc, ok := c.matchBlockComment(line, i); ok {
+			c.inMultiLine = true
+			c.block = bc
+			c.blockIsDoc = isDocComment(line[i:], lang.DocComments)
+			c.startComment()
+			c.lineHasComment = true
+			c.lineHasDoc = c.lineHasDoc || c.blockIsDoc
+			i += len(bc.Start)
+			continue
+		}
 
-			// Check for single line comment
-			if lang.SingleLineComment != "" && strings.HasPrefix(line[i:], lang.SingleLineComment) {
-				lineHasComment = true
-				break // Rest of line is comment
+		// Check for single line comment
+		if marker := c.matchLineComment(line, i); marker != "" {
+			// A marker anchored to a column flags the whole line as a
+			// comment, like COBOL's indicator area after sequence numbers
+			if lang.MarkerRules[marker].Column > 0 {
+				c.lineHasCode = false
 			}
+			c.lineHasComment = true
+			c.lineHasDoc = c.lineHasDoc || isDocComment(line[i:], lang.DocComments)
+			c.restIsComment = true
+			c.startComment()
+			c.addComment(line[i:])
+			return len(line) // Rest of line is comment
+		}
 
-			// Check for multi-line comment start
-			if lang.MultiLineStart != "" && strings.HasPrefix(line[i:], lang.MultiLineStart) {
-				inMultiLine = true
-				lineHasComment = true
-				i += len(lang.MultiLineStart)
+		// Check for a here-document, before strings since its word may
+		// be quoted
+		if end, n, ok := matchHeredoc(line, i, lang.Heredocs); ok {
+			end.line = c.lines.line
+			c.newHeredocs = append(c.newHeredocs, end)
+			c.lineHasCode = true
+			c.lastCodeChar = line[i+n-1]
+			i += n
+			continue
+		}
+
+		// Check for string start
+		if lit, n, end, ok := matchStringLiteral(line, i, lang.Strings); ok {
+			if lit.Char {
+				c.lineHasCode = true
+				c.lastCodeChar = line[i+n-1]
+				i += n
 				continue
 			}
 
-			// Check for string start
-			foundString := false
-			for _, delim := range lang.StringDelimiters {
-				if strings.HasPrefix(line[i:], delim) {
-					inString = true
-					stringEnd = delim
-					lineHasCode = true
-					i += len(delim)
-					foundString = true
-					break
-				}
-			}
-			if foundString {
+			// A docstring is counted like a multi-line comment
+			if c.docStringPos && !c.lineHasCode && containsString(lang.DocStringDelimiters, lit.Start) {
+				c.inMultiLine = true
+				c.block = BlockComment{Start: lit.Start, End: end}
+				c.blockIsDoc = true
+				c.startComment()
+				c.docStringPos = false
+				c.lineHasComment = true
+				c.lineHasDoc = true
+				i += n
 				continue
 			}
 
-			// Check for code
-			if !isWhitespace(line[i]) {
-				lineHasCode = true
+			c.inString = true
+			c.str = lit
+			c.stringEnd = end
+			c.stringLine = c.lines.line
+			c.lineHasCode = true
+			i += n
+			continue
+		}
+
+		// Check for a branch keyword or operator, except in a directive
+		// such as #if, which the preprocessor resolves
+		if n := matchBranch(line, i, lang.Branches); n > 0 && !c.lineDirective {
+			c.stats.Complexity++
+			c.lineHasCode = true
+			c.lastCodeChar = line[i+n-1]
+			i += n
+			continue
+		}
+
+		// Check for code
+		if !isWhitespace(line[i]) {
+			c.lineHasCode = true
+			c.prevCodeChar = c.lastCodeChar
+			c.lastCodeChar = line[i]
+			c.trackStatement(line[i])
+			switch line[i] {
+			case '(', '[', '{':
+				c.bracketDepth++
+			case ')', ']', '}':
+				c.bracketDepth--
 			}
-			i++
 		}
+		i++
+	}
+	return i
+}
+
+// endLine classifies the scanned line and updates the statistics
+func (c *lineCounter) endLine() {
+	lang := c.lang
+	if c.shebang {
+		c.stats.CodeLines++
+		return
+	}
+
+	// Only multi-line literals, or those whose newline is escaped, carry on
+	// to the next line
+	if c.inString && !c.str.MultiLine && !c.escapedNewline {
+		c.inString = false
+		c.lines.report(c.stringLine, "unterminated string literal")
+	}
+
+	// Any statement other than a scope header ends docstring position;
+	// a complete header ending in ':' opens a body whose first statement
+	// may be one
+	if c.lineHasCode && len(lang.DocStringDelimiters) > 0 {
+		if !c.inScopeHeader {
+			c.docStringPos = false
+		} else if !c.inString && c.bracketDepth <= 0 && c.lastCodeChar != '\\' {
+			c.docStringPos = c.lastCodeChar == ':'
+			c.inScopeHeader = false
+		}
+	}
 
-		if lineHasCode {
-			stats.CodeLines++
-		} else if lineHasComment {
-			stats.CommentLines++
-		} else if stats.TotalLines > 0 { // Should always be true here
-			// Check if it's truly blank or just whitespace
-			stats.BlankLines++
+	if c.lineHasCode {
+		c.stats.CodeLines++
+		if c.lineHasComment {
+			c.stats.MixedLines++
+		}
+		c.trackDeclaration()
+		c.trackStatementEnd()
+	} else if c.lineHasComment {
+		c.stats.CommentLines++
+		if c.lineHasDoc {
+			c.stats.DocLines++
 		}
+		if c.disabled {
+			c.stats.DisabledLines++
+		}
+	} else {
+		c.stats.BlankLines++
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	if len(c.comment) > 0 {
+		c.trackAnnotations()
 	}
 
-	return stats, nil
+	c.trackDocCommentsBefore(c.head, c.lineHasCode, c.lineHasComment && !c.lineHasDoc)
+	c.head = ""
+
+	c.heredocs = append(c.heredocs, c.newHeredocs...)
+	c.newHeredocs = nil
+}
+
+// trackDocCommentsBefore counts a group of comment lines as documentation
+// once the line directly below it turns out to be a documented declaration,
+// like an exported Go function
+func (c *lineCounter) trackDocCommentsBefore(line string, hasCode, plainComment bool) {
+	if c.lang.DocCommentsBefore == nil {
+		return
+	}
+	switch {
+	case hasCode:
+		if c.pendingDoc > 0 && c.lang.DocCommentsBefore.MatchString(strings.TrimSpace(line)) {
+			c.stats.DocLines += c.pendingDoc
+		}
+		c.pendingDoc = 0
+	case plainComment:
+		c.pendingDoc++
+	default:
+		c.pendingDoc = 0
+	}
 }
 
 func isWhitespace(c byte) bool {
 	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
 }
 
+// matchLineComment returns the line comment marker starting at line[i]
+func (c *lineCounter) matchLineComment(line string, i int) string {
+	for _, marker := range c.lang.LineComments {
+		if c.markerAt(line, i, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// matchBranch returns the length of the branch keyword or operator at
+// line[i], or 0 if there is none. Keywords must be whole words.
+func matchBranch(line string, i int, branches []string) int {
+	if len(branches) == 0 || i > 0 && isIdentChar(line[i-1]) && isIdentChar(line[i]) {
+		return 0
+	}
+	for _, branch := range branches {
+		if !strings.HasPrefix(line[i:], branch) {
+			continue
+		}
+		end := i + len(branch)
+		if end < len(line) && isIdentChar(line[end]) && isIdentChar(branch[len(branch)-1]) {
+			continue
+		}
+		return len(branch)
+	}
+	return 0
+}
+
+// matchBlockComment returns the first block comment whose start marker
+// begins at line[i]
+func (c *lineCounter) matchBlockComment(line string, i int) (BlockComment, bool) {
+	for _, bc := range c.lang.BlockComments {
+		if bc.Start != "" && c.markerAt(line, i, bc.Start) {
+			return bc, true
+		}
+	}
+	return BlockComment{}, false
+}
+
+// markerAt reports whether a comment marker begins at line[i], subject to
+// any rule the language sets for it
+func (c *lineCounter) markerAt(line string, i int, marker string) bool {
+	rule, ok := c.lang.MarkerRules[marker]
+	if !ok {
+		return strings.HasPrefix(line[i:], marker)
+	}
+	return rule.matches(line, i, c.offset+i+1, marker)
+}
+
+// matches reports whether marker begins at line[i], which is at the given
+// column of its line
+func (r MarkerRule) matches(line string, i, column int, marker string) bool {
+	if r.Column > 0 && column != r.Column {
+		return false
+	}
+	end := i + len(marker)
+	if end > len(line) {
+		return false
+	}
+	if r.IgnoreCase && !strings.EqualFold(line[i:end], marker) || !r.IgnoreCase && line[i:end] != marker {
+		return false
+	}
+
+	// A word boundary only matters where the marker itself is alphanumeric,
+	// so @REM may follow anything but must end the word
+	if r.Word {
+		if i > 0 && isIdentChar(line[i-1]) && isIdentChar(marker[0]) {
+			return false
+		}
+		if end < len(line) && isIdentChar(line[end]) && isIdentChar(marker[len(marker)-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isDocComment reports whether the comment at the start of s opens with one
+// of the documentation markers. A marker followed by its own last character,
+// as in //// or /***, is a plain comment.
+func isDocComment(s string, markers []string) bool {
+	for _, m := range markers {
+		if strings.HasPrefix(s, m) && (len(s) == len(m) || s[len(m)] != m[len(m)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isScopeHeader reports whether the line starts with one of the keywords that
+// introduce a scope, such as Python's def and class
+func isScopeHeader(line string, keywords []string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, kw := range keywords {
+		if strings.HasPrefix(trimmed, kw) && len(trimmed) > len(kw) && isWhitespace(trimmed[len(kw)]) {
+			return true
+		}
+	}
+	return false
+}
+
 // CountLinesGeneric counts lines for files without specific language support
 func CountLinesGeneric(filePath string) (*FileStats, error) {
 	file, err := os.Open(filePath)
@@ -183,34 +744,57 @@ func CountLinesGeneric(filePath string) (*FileStats, error) {
 	}
 	defer file.Close()
 
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
 	stats := &FileStats{
 		FilePath: filePath,
 		Language: "Unknown",
+		Bytes:    int(info.Size()),
 	}
 
-	scanner := bufio.NewScanner(file)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		trimmedLine := strings.TrimSpace(line)
-		stats.TotalLines++
-
-		if trimmedLine == "" {
-			stats.BlankLines++
-		} else {
-			stats.CodeLines++
-		}
-	}
+	text, encoding := decodeText(file)
+	stats.Encoding = encoding
 
-	if err := scanner.Err(); err != nil {
+	lines := &lineTracker{stats: stats}
+	shape, err := countStream(text, &blankCounter{stats: stats}, lines)
+	if err != nil {
 		return nil, err
 	}
+	stats.LineEndings = shape.lineEndings
+	stats.Characters = shape.characters
 
 	return stats, nil
 }
 
+// blankCounter counts every line that is not blank as code
+type blankCounter struct {
+	stats   *FileStats
+	hasCode bool
+}
+
+func (b *blankCounter) beginLine(head string) {
+	b.stats.TotalLines++
+	b.hasCode = false
+}
+
+func (b *blankCounter) scan(text string, start int, final bool) int {
+	b.hasCode = b.hasCode || strings.TrimSpace(text[start:]) != ""
+	return len(text)
+}
+
+func (b *blankCounter) endLine() {
+	if b.hasCode {
+		b.stats.CodeLines++
+	} else {
+		b.stats.BlankLines++
+	}
+}
+
+func (b *blankCounter) finish() {}
+
 // AggregateStats aggregates file statistics by language
 func AggregateStats(fileStats []*FileStats) map[string]*LanguageStats {
 	langStats := make(map[string]*LanguageStats)
@@ -220,23 +804,89 @@ func AggregateStats(fileStats []*FileStats) map[string]*LanguageStats {
 			continue
 		}
 
-		lang := fs.Language
-		if _, exists := langStats[lang]; !exists {
-			langStats[lang] = &LanguageStats{
-				Language: lang,
-			}
-		}
+		ls := languageStatsFor(langStats, fs.Language)
+		ls.FileCount++
+		ls.addLines(fs)
 
-		langStats[lang].FileCount++
-		langStats[lang].BlankLines += fs.BlankLines
-		langStats[lang].CommentLines += fs.CommentLines
-		langStats[lang].CodeLines += fs.CodeLines
-		langStats[lang].TotalLines += fs.TotalLines
+		// Embedded regions add lines to their own language, not files
+		for _, child := range fs.Embedded {
+			languageStatsFor(langStats, child.Language).addLines(child)
+		}
 	}
 
 	return langStats
 }
 
+// MostComplexFiles returns up to n files in order of decreasing complexity,
+// including that of their embedded regions
+func MostComplexFiles(fileStats []*FileStats, n int) []*FileStats {
+	if n <= 0 {
+		return nil
+	}
+
+	var files []*FileStats
+	for _, fs := range fileStats {
+		if fs != nil && fileComplexity(fs) > 0 {
+			files = append(files, fs)
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		ci, cj := fileComplexity(files[i]), fileComplexity(files[j])
+		if ci != cj {
+			return ci > cj
+		}
+		return files[i].FilePath < files[j].FilePath
+	})
+
+	if len(files) > n {
+		files = files[:n]
+	}
+	return files
+}
+
+// fileComplexity returns the complexity of a file and its embedded regions
+func fileComplexity(fs *FileStats) int {
+	complexity := fs.Complexity
+	for _, child := range fs.Embedded {
+		complexity += child.Complexity
+	}
+	return complexity
+}
+
+// languageStatsFor returns the entry for lang, creating it if needed
+func languageStatsFor(langStats map[string]*LanguageStats, lang string) *LanguageStats {
+	ls, exists := langStats[lang]
+	if !exists {
+		ls = &LanguageStats{
+			Language: lang,
+		}
+		langStats[lang] = ls
+	}
+	return ls
+}
+
+// addLines adds the line counts of a file to the language statistics
+func (ls *LanguageStats) addLines(fs *FileStats) {
+	ls.BlankLines += fs.BlankLines
+	ls.CommentLines += fs.CommentLines
+	ls.CodeLines += fs.CodeLines
+	ls.LogicalLines += fs.LogicalLines
+	ls.TotalLines += fs.TotalLines
+	ls.MixedLines += fs.MixedLines
+	ls.DocLines += fs.DocLines
+	ls.DisabledLines += fs.DisabledLines
+	ls.Complexity += fs.Complexity
+	ls.Functions += fs.Functions
+	ls.Types += fs.Types
+	ls.Interfaces += fs.Interfaces
+	ls.FunctionLines += fs.FunctionLines
+	ls.Bytes += fs.Bytes
+	ls.Characters += fs.Characters
+	ls.addUniqueLines(fs.lineHashes)
+	ls.addAnnotations(fs.Annotations)
+}
+
 // TotalStats calculates the total statistics across all languages
 func TotalStats(langStats map[string]*LanguageStats) *LanguageStats {
 	total := &LanguageStats{
@@ -248,7 +898,20 @@ func TotalStats(langStats map[string]*LanguageStats) *LanguageStats {
 		total.BlankLines += ls.BlankLines
 		total.CommentLines += ls.CommentLines
 		total.CodeLines += ls.CodeLines
+		total.LogicalLines += ls.LogicalLines
 		total.TotalLines += ls.TotalLines
+		total.MixedLines += ls.MixedLines
+		total.DocLines += ls.DocLines
+		total.DisabledLines += ls.DisabledLines
+		total.Complexity += ls.Complexity
+		total.Functions += ls.Functions
+		total.Types += ls.Types
+		total.Interfaces += ls.Interfaces
+		total.FunctionLines += ls.FunctionLines
+		total.Bytes += ls.Bytes
+		total.Characters += ls.Characters
+		total.addUniqueLines(ls.lineHashes)
+		total.addAnnotations(ls.Annotations)
 	}
 
 	return total
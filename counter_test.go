@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -60,6 +61,47 @@ def hello():
 			wantCode:    2,
 			wantTotal:   5,
 		},
+		{
+			name:     "Python docstrings",
+			filename: "docstrings.py",
+			content: `"""Module docstring."""
+import os
+
+
+class Greeter:
+    '''Class docstring
+    spanning lines.
+    '''
+
+    def greet(self,
+              name):
+        """Say hello."""
+        return "Hello " + name
+`,
+			lang:        Languages[".py"],
+			wantBlank:   3,
+			wantComment: 5,
+			wantCode:    5,
+			wantTotal:   13,
+		},
+		{
+			name:     "Python triple-quoted strings",
+			filename: "query.py",
+			content: `def load(db):
+    x = 1
+    """Not a docstring."""
+    query = """
+        SELECT * -- all columns
+        FROM users
+    """
+    return db.run(query)
+`,
+			lang:        Languages[".py"],
+			wantBlank:   0,
+			wantComment: 0,
+			wantCode:    8,
+			wantTotal:   8,
+		},
 		{
 			name:     "JavaScript file",
 			filename: "test.js",
@@ -91,6 +133,169 @@ console.log(x);
 			wantCode:    5,
 			wantTotal:   6,
 		},
+		{
+			name:     "Terraform with both comment styles",
+			filename: "main.tf",
+			content: `# Provider configuration
+// Managed by the platform team
+provider "aws" {
+  region = "eu-west-1" # default region
+}
+/* legacy
+   block */
+`,
+			lang:        Languages[".tf"],
+			wantBlank:   0,
+			wantComment: 4,
+			wantCode:    3,
+			wantTotal:   7,
+		},
+		{
+			name:     "Lua block comment",
+			filename: "test.lua",
+			content: `--[[ A block
+comment ]]
+-- line comment
+print("hi")
+`,
+			lang:        Languages[".lua"],
+			wantBlank:   0,
+			wantComment: 3,
+			wantCode:    1,
+			wantTotal:   4,
+		},
+		{
+			name:     "Batch file",
+			filename: "build.bat",
+			content: `@echo off
+REM Build the project
+:: Another comment
+call mvn package
+`,
+			lang:        FilenameLanguages["gradlew.bat"],
+			wantBlank:   0,
+			wantComment: 2,
+			wantCode:    2,
+			wantTotal:   4,
+		},
+		{
+			name:     "Rust raw strings",
+			filename: "raw.rs",
+			content: `let url = r#"http://example.com/*"#;
+let re = r"\d+ // digits";
+// real comment
+`,
+			lang:        Languages[".rs"],
+			wantBlank:   0,
+			wantComment: 1,
+			wantCode:    2,
+			wantTotal:   3,
+		},
+		{
+			name:     "C++ raw string",
+			filename: "raw.cpp",
+			content: `const char* q = R"sql(
+  SELECT 1 /* not a comment */
+  -- ")" inside
+)sql";
+// real comment
+`,
+			lang:        Languages[".cpp"],
+			wantBlank:   0,
+			wantComment: 1,
+			wantCode:    4,
+			wantTotal:   5,
+		},
+		{
+			name:     "C# verbatim string",
+			filename: "paths.cs",
+			content: `var dir = @"C:\temp\";
+var quote = @"He said ""// hi""";
+// real comment
+`,
+			lang:        Languages[".cs"],
+			wantBlank:   0,
+			wantComment: 1,
+			wantCode:    2,
+			wantTotal:   3,
+		},
+		{
+			name:     "SQL doubled quotes",
+			filename: "query.sql",
+			content: `SELECT 'it''s -- not a comment' AS a;
+-- real comment
+SELECT "col""#1" FROM t;
+`,
+			lang:        Languages[".sql"],
+			wantBlank:   0,
+			wantComment: 1,
+			wantCode:    2,
+			wantTotal:   3,
+		},
+		{
+			name:        "Go raw string with backslash",
+			filename:    "raw.go",
+			content:     "var p = `C:\\`\n// real comment\nvar s = \"unterminated\n// also real\n",
+			lang:        Languages[".go"],
+			wantBlank:   0,
+			wantComment: 2,
+			wantCode:    2,
+			wantTotal:   4,
+		},
+		{
+			name:     "Rust lifetimes and char literals",
+			filename: "life.rs",
+			content: `fn first<'a>(x: &'a str) -> &'a str {
+    let q = '"'; // quote char
+    let e = '\''; let u = '\u{1F600}';
+    x
+}
+// real comment
+/* block */
+`,
+			lang:        Languages[".rs"],
+			wantBlank:   0,
+			wantComment: 2,
+			wantCode:    5,
+			wantTotal:   7,
+		},
+		{
+			name:     "Java char literals",
+			filename: "Chars.java",
+			content: `char q = '"';
+String s = "it's";
+// real comment
+`,
+			lang:        Languages[".java"],
+			wantBlank:   0,
+			wantComment: 1,
+			wantCode:    2,
+			wantTotal:   3,
+		},
+		{
+			name:     "OCaml type variables",
+			filename: "list.ml",
+			content: `let id (x : 'a) : 'a = x
+(* real comment *)
+`,
+			lang:        Languages[".ml"],
+			wantBlank:   0,
+			wantComment: 1,
+			wantCode:    1,
+			wantTotal:   2,
+		},
+		{
+			name:     "Visual Basic apostrophe comments",
+			filename: "module.vb",
+			content: `Dim s = "it's" ' trailing
+' real comment
+`,
+			lang:        Languages[".vb"],
+			wantBlank:   0,
+			wantComment: 1,
+			wantCode:    1,
+			wantTotal:   2,
+		},
 		{
 			name:        "Empty file",
 			filename:    "empty.go",
@@ -141,8 +346,8 @@ exit 0
 `,
 			lang:        Languages[".sh"],
 			wantBlank:   1,
-			wantComment: 3,
-			wantCode:    2,
+			wantComment: 2,
+			wantCode:    3,
 			wantTotal:   6,
 		},
 		{
@@ -333,3 +538,609 @@ func TestAggregateStatsWithNil(t *testing.T) {
 		t.Errorf("Go FileCount = %d, want 2", goStats.FileCount)
 	}
 }
+
+func TestAggregateStatsEmbedded(t *testing.T) {
+	fileStats := []*FileStats{
+		{
+			Language: "Vue", BlankLines: 2, CodeLines: 8, TotalLines: 10,
+			Embedded: []*FileStats{
+				{Language: "TypeScript", CommentLines: 1, CodeLines: 20, TotalLines: 21},
+			},
+		},
+		{Language: "TypeScript", CodeLines: 5, TotalLines: 5},
+	}
+
+	langStats := AggregateStats(fileStats)
+
+	vue := langStats["Vue"]
+	if vue == nil || vue.FileCount != 1 || vue.CodeLines != 8 {
+		t.Errorf("Vue stats = %+v, want 1 file with 8 code lines", vue)
+	}
+
+	ts := langStats["TypeScript"]
+	if ts == nil {
+		t.Fatal("TypeScript stats not found")
+	}
+	if ts.FileCount != 1 {
+		t.Errorf("TypeScript FileCount = %d, want 1", ts.FileCount)
+	}
+	if ts.CodeLines != 25 {
+		t.Errorf("TypeScript CodeLines = %d, want 25", ts.CodeLines)
+	}
+
+	total := TotalStats(langStats)
+	if total.TotalLines != 36 {
+		t.Errorf("Total TotalLines = %d, want 36", total.TotalLines)
+	}
+}
+
+func TestCountLinesDiagnostics(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name      string
+		filename  string
+		content   string
+		lang      *Language
+		wantLines []int
+	}{
+		{
+			name:     "Unterminated single-line string",
+			filename: "stray.c",
+			content: `char *s = "oops;
+// still a comment
+int x = 1;
+`,
+			lang:      Languages[".c"],
+			wantLines: []int{1},
+		},
+		{
+			name:      "Escaped newline continues the string",
+			filename:  "cont.c",
+			content:   "char *s = \"a\\\nb\";\n",
+			lang:      Languages[".c"],
+			wantLines: nil,
+		},
+		{
+			name:      "Unterminated multi-line string",
+			filename:  "open.go",
+			content:   "package main\n\nvar s = `never closed\n// swallowed\n",
+			lang:      Languages[".go"],
+			wantLines: []int{3},
+		},
+		{
+			name:      "Unterminated string in embedded region",
+			filename:  "App.vue",
+			content:   "<template>\n</template>\n<script>\nconst s = \"oops\n</script>\n",
+			lang:      Languages[".vue"],
+			wantLines: []int{4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, tt.lang)
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			var gotLines []int
+			for _, d := range stats.Diagnostics {
+				gotLines = append(gotLines, d.Line)
+			}
+			if !reflect.DeepEqual(gotLines, tt.wantLines) {
+				t.Errorf("diagnostic lines = %v, want %v", gotLines, tt.wantLines)
+			}
+		})
+	}
+
+	// The line after an unterminated string is classified normally again
+	stats, err := CountLines(filepath.Join(tmpDir, "stray.c"), Languages[".c"])
+	if err != nil {
+		t.Fatalf("CountLines failed: %v", err)
+	}
+	if stats.CommentLines != 1 {
+		t.Errorf("CommentLines = %d, want 1", stats.CommentLines)
+	}
+}
+
+func TestCountLinesMixed(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	content := `type Config struct {
+	Path    string // root to scan
+	Workers int    /* pool size */
+	// Hidden controls dot files
+	Hidden bool
+}
+`
+	filePath := filepath.Join(tmpDir, "config.go")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	stats, err := CountLines(filePath, Languages[".go"])
+	if err != nil {
+		t.Fatalf("CountLines failed: %v", err)
+	}
+
+	if stats.MixedLines != 2 {
+		t.Errorf("MixedLines = %d, want 2", stats.MixedLines)
+	}
+	if stats.CodeLines != 5 {
+		t.Errorf("CodeLines = %d, want 5", stats.CodeLines)
+	}
+	if stats.CommentLines != 1 {
+		t.Errorf("CommentLines = %d, want 1", stats.CommentLines)
+	}
+
+	langStats := AggregateStats([]*FileStats{stats, stats})
+	if got := TotalStats(langStats).MixedLines; got != 4 {
+		t.Errorf("Total MixedLines = %d, want 4", got)
+	}
+}
+
+func TestCountLinesDoc(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name        string
+		filename    string
+		content     string
+		lang        *Language
+		wantComment int
+		wantDoc     int
+	}{
+		{
+			name:     "Rust doc comments",
+			filename: "lib.rs",
+			content: `//! Crate docs
+/// Adds one.
+//// banner, not docs
+// plain comment
+fn add(x: i32) -> i32 { x + 1 }
+`,
+			lang:        Languages[".rs"],
+			wantComment: 4,
+			wantDoc:     2,
+		},
+		{
+			name:     "Javadoc block",
+			filename: "Api.java",
+			content: `/**
+ * Returns the answer.
+ */
+/* plain block */
+int answer() { return 42; }
+`,
+			lang:        Languages[".java"],
+			wantComment: 4,
+			wantDoc:     3,
+		},
+		{
+			name:     "Go comments above exported declarations",
+			filename: "api.go",
+			content: `// Package api does things.
+package api
+
+// Run starts the service
+// and blocks.
+func Run() {}
+
+// helper is unexported
+func helper() {}
+
+// Detached comment
+
+type Config struct{}
+
+// Close releases resources
+func (s *Server) Close() {}
+`,
+			lang:        Languages[".go"],
+			wantComment: 6,
+			wantDoc:     4,
+		},
+		{
+			name:     "Python docstrings",
+			filename: "mod.py",
+			content: `"""Module docs."""
+# plain comment
+def f():
+    """Function docs
+    on two lines."""
+`,
+			lang:        Languages[".py"],
+			wantComment: 4,
+			wantDoc:     3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, tt.lang)
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			if stats.CommentLines != tt.wantComment {
+				t.Errorf("CommentLines = %d, want %d", stats.CommentLines, tt.wantComment)
+			}
+			if stats.DocLines != tt.wantDoc {
+				t.Errorf("DocLines = %d, want %d", stats.DocLines, tt.wantDoc)
+			}
+		})
+	}
+}
+
+func TestCountLinesHeredocs(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name            string
+		filename        string
+		content         string
+		wantBlank       int
+		wantComment     int
+		wantCode        int
+		wantDiagnostics int
+	}{
+		{
+			name:     "Shell YAML heredoc",
+			filename: "deploy.sh",
+			content: `# Write the config
+cat <<EOF > config.yaml
+# replicas for production
+replicas: 3
+
+image: "app:latest
+EOF
+echo done # finished
+`,
+			wantBlank:   1,
+			wantComment: 1,
+			wantCode:    6,
+		},
+		{
+			name:        "Shell indented terminator",
+			filename:    "indent.sh",
+			content:     "if true; then\n\tcat <<-'END'\n\t# not a comment\n\tEND\nfi\n# comment\n",
+			wantComment: 1,
+			wantCode:    5,
+		},
+		{
+			name:        "Shell terminator must not be indented",
+			filename:    "strict.sh",
+			content:     "cat <<EOF\n  EOF\n# still inside\nEOF\n# comment\n",
+			wantComment: 1,
+			wantCode:    4,
+		},
+		{
+			name:        "Two heredocs on one line",
+			filename:    "two.sh",
+			content:     "paste <<A <<B\n# a\nA\n# b\nB\n# comment\n",
+			wantComment: 1,
+			wantCode:    5,
+		},
+		{
+			name:     "Ruby squiggly heredoc",
+			filename: "query.rb",
+			content: `sql = <<~SQL
+  -- pick users
+  SELECT * FROM users # all of them
+SQL
+# comment
+`,
+			wantComment: 1,
+			wantCode:    4,
+		},
+		{
+			name:        "Perl heredoc",
+			filename:    "report.pl",
+			content:     "print <<\"EOT\";\n# Heading\nit's here\nEOT\n# comment\n",
+			wantComment: 1,
+			wantCode:    4,
+		},
+		{
+			name:        "PHP nowdoc with indented terminator",
+			filename:    "page.php",
+			content:     "<?php\n$t = <<<'HTML'\n    <!-- # markup -->\n    HTML;\n// comment\n",
+			wantComment: 1,
+			wantCode:    4,
+		},
+		{
+			name:        "Code after a PHP terminator",
+			filename:    "tail.php",
+			content:     "<?php\n$a = <<<EOT\ntext\nEOT . \"/* x\"; /* start\nstill a comment */\n",
+			wantComment: 1,
+			wantCode:    4,
+		},
+		{
+			name:            "Unterminated heredoc",
+			filename:        "broken.sh",
+			content:         "cat <<EOF\n# body\n",
+			wantCode:        2,
+			wantDiagnostics: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, Languages[filepath.Ext(tt.filename)])
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			if stats.BlankLines != tt.wantBlank {
+				t.Errorf("BlankLines = %d, want %d", stats.BlankLines, tt.wantBlank)
+			}
+			if stats.CommentLines != tt.wantComment {
+				t.Errorf("CommentLines = %d, want %d", stats.CommentLines, tt.wantComment)
+			}
+			if stats.CodeLines != tt.wantCode {
+				t.Errorf("CodeLines = %d, want %d", stats.CodeLines, tt.wantCode)
+			}
+			if len(stats.Diagnostics) != tt.wantDiagnostics {
+				t.Errorf("Diagnostics = %v, want %d", stats.Diagnostics, tt.wantDiagnostics)
+			}
+		})
+	}
+}
+
+func TestCountLinesMarkerRules(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name        string
+		filename    string
+		content     string
+		wantComment int
+		wantCode    int
+	}{
+		{
+			name:     "Ruby =begin mid-line is code",
+			filename: "value.rb",
+			content:  "x=begin_value\n  =begin\ny = 2\n",
+			wantCode: 3,
+		},
+		{
+			name:        "Ruby =begin at column 0",
+			filename:    "doc.rb",
+			content:     "=begin\nnotes\n  =end\n=end\nx = 1\n",
+			wantComment: 4,
+			wantCode:    1,
+		},
+		{
+			name:        "Perl POD",
+			filename:    "Module.pm",
+			content:     "package Module;\n=head1 NAME\n\nModule - does things\n\n=cut\nmy $pod = 1; # x=pod\n",
+			wantComment: 3,
+			wantCode:    2,
+		},
+		{
+			name:        "Batch REM is a case-insensitive word",
+			filename:    "build.bat",
+			content:     "rem Build\n@Rem quiet\nREM.\necho remark\nset REMOTE=1\n:: label comment\n",
+			wantComment: 4,
+			wantCode:    2,
+		},
+		{
+			name:        "Fixed-form Fortran",
+			filename:    "solve.f",
+			content:     "C     Solve the system\nc     lower case\n*     star\n      CALL SOLVE(A, B) ! inline\n      X = 'C'\n",
+			wantComment: 3,
+			wantCode:    2,
+		},
+		{
+			name:        "Free-form Fortran",
+			filename:    "solve.f90",
+			content:     "! Solve the system\ncall solve(a, b)\n",
+			wantComment: 1,
+			wantCode:    1,
+		},
+		{
+			name:        "COBOL indicator area",
+			filename:    "report.cbl",
+			content:     "000100* Print the report\n      / new page\n       DISPLAY 'A*B'. *> inline\n       COMPUTE X = A * B.\n",
+			wantComment: 2,
+			wantCode:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, Languages[filepath.Ext(tt.filename)])
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			if stats.CommentLines != tt.wantComment {
+				t.Errorf("CommentLines = %d, want %d", stats.CommentLines, tt.wantComment)
+			}
+			if stats.CodeLines != tt.wantCode {
+				t.Errorf("CodeLines = %d, want %d", stats.CodeLines, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestMarkerRuleMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   MarkerRule
+		line   string
+		i      int
+		column int
+		marker string
+		want   bool
+	}{
+		{"any column", MarkerRule{}, "  x", 2, 3, "x", true},
+		{"fixed column", MarkerRule{Column: 7}, "      *", 6, 7, "*", true},
+		{"wrong column", MarkerRule{Column: 7}, "     *", 5, 6, "*", false},
+		{"word", MarkerRule{Word: true}, "REM x", 0, 1, "REM", true},
+		{"word continues", MarkerRule{Word: true}, "REMOTE", 0, 1, "REM", false},
+		{"word preceded", MarkerRule{Word: true}, "xREM", 1, 2, "REM", false},
+		{"case sensitive", MarkerRule{Word: true}, "rem", 0, 1, "REM", false},
+		{"ignore case", MarkerRule{IgnoreCase: true}, "rem", 0, 1, "REM", true},
+		{"too short", MarkerRule{}, "RE", 0, 1, "REM", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.matches(tt.line, tt.i, tt.column, tt.marker); got != tt.want {
+				t.Errorf("matches(%q, %d, %d, %q) = %v, want %v", tt.line, tt.i, tt.column, tt.marker, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountLinesComplexity(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name           string
+		filename       string
+		content        string
+		wantComplexity int
+	}{
+		{
+			name:     "Go branches",
+			filename: "main.go",
+			content: `package main
+
+func classify(n int) string {
+	if n < 0 && n != -1 { // if negative
+		return "negative"
+	}
+	for i := 0; i < n; i++ {
+		switch {
+		case i > 10 || i == 5:
+			return "if && for"
+		}
+	}
+	/* if for case */
+	return "diff"
+}
+`,
+			wantComplexity: 5,
+		},
+		{
+			name:           "Keywords must be whole words",
+			filename:       "words.go",
+			content:        "var iffy, format, notif, cases = 1, 2, 3, 4\n",
+			wantComplexity: 0,
+		},
+		{
+			name:           "Python keyword operators",
+			filename:       "check.py",
+			content:        "if a and b or c:\n    pass\nelif d:\n    pass\n# if and or\nx = 'for while'\n",
+			wantComplexity: 4,
+		},
+		{
+			name:           "Preprocessor directives are not branches",
+			filename:       "feature.c",
+			content:        "#if 0\nint old;\n#elif defined(A) && B\nint a;\n#endif\n#if 1\nint f(int x) { if (x) return 1; return 0; }\n#endif\n",
+			wantComplexity: 1,
+		},
+		{
+			name:           "Languages without branch keywords",
+			filename:       "config.yaml",
+			content:        "if: true\nfor: false\n",
+			wantComplexity: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, Languages[filepath.Ext(tt.filename)])
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			if stats.Complexity != tt.wantComplexity {
+				t.Errorf("Complexity = %d, want %d", stats.Complexity, tt.wantComplexity)
+			}
+		})
+	}
+}
+
+func TestMostComplexFiles(t *testing.T) {
+	fileStats := []*FileStats{
+		{FilePath: "b.go", Complexity: 5},
+		{FilePath: "simple.go"},
+		nil,
+		{FilePath: "a.go", Complexity: 5},
+		{FilePath: "app.vue", Complexity: 1, Embedded: []*FileStats{{Complexity: 8}}},
+	}
+
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"Top two", 2, []string{"app.vue", "a.go"}},
+		{"All complex files", 10, []string{"app.vue", "a.go", "b.go"}},
+		{"Disabled", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, fs := range MostComplexFiles(fileStats, tt.n) {
+				got = append(got, fs.FilePath)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MostComplexFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import "regexp"
+
+// Declarations holds patterns matching code lines that declare a function or
+// method, a type or class, or an interface. Each pattern is matched against
+// the whole line, including its indentation; interfaces are tried first,
+// then types, then functions.
+type Declarations struct {
+	Functions  *regexp.Regexp
+	Types      *regexp.Regexp
+	Interfaces *regexp.Regexp
+
+	// NotFunctions, if set, matches statements that Functions would take for
+	// a declaration, such as "if (ready) {" or "return compute(x);"
+	NotFunctions *regexp.Regexp
+}
+
+// declKind is the kind of declaration found on a line
+type declKind int
+
+const (
+	declNone declKind = iota
+	declFunction
+	declType
+	declInterface
+)
+
+// match returns the kind of declaration on a line
+func (d *Declarations) match(line string) declKind {
+	switch {
+	case d.Interfaces != nil && d.Interfaces.MatchString(line):
+		return declInterface
+	case d.Types != nil && d.Types.MatchString(line):
+		return declType
+	case d.Functions != nil && d.Functions.MatchString(line) && (d.NotFunctions == nil || !d.NotFunctions.MatchString(line)):
+		return declFunction
+	}
+	return declNone
+}
+
+// Modifiers that may precede a declaration in Java and C#
+const (
+	javaModifiers   = `(?:(?:public|private|protected|static|final|abstract|synchronized|native|default|strictfp|sealed|non-sealed)\s+)`
+	csharpModifiers = `(?:(?:public|private|protected|internal|static|readonly|abstract|sealed|virtual|override|async|extern|unsafe|partial|new)\s+)`
+)
+
+// cFunction matches a C or C++ function definition starting at column 0, as
+// in "static int count(void) {" or "Foo::~Foo()". Statements are indented,
+// so this is a cheap way to tell calls from definitions.
+const cFunction = `^[A-Za-z_][\w\s*&:<>,]*[\s*&:]~?\w+\s*\([^;]*$`
+
+// jsMethod matches an indented JavaScript or TypeScript class or object
+// method whose body opens on the same line, as in "async load(id) {" or
+// "get size(): number {"
+const jsMethod = `^\s+((static|async|get|set|public|private|protected|readonly|override|abstract)\s+)*[*#]?[A-Za-z_$][\w$]*\s*(<[^>]*>)?\s*\([^)]*\)\s*(:[^{=;]+)?\{`
+
+var (
+	goDeclarations = &Declarations{
+		Functions:  regexp.MustCompile(`^func\s`),
+		Types:      regexp.MustCompile(`^type\s+\w+`),
+		Interfaces: regexp.MustCompile(`^type\s+\w+(\[[^\]]*\])?\s+interface\b`),
+	}
+	pyDeclarations = &Declarations{
+		Functions:  regexp.MustCompile(`^\s*(async\s+)?def\s+\w+`),
+		Types:      regexp.MustCompile(`^\s*class\s+\w+`),
+		Interfaces: regexp.MustCompile(`^\s*class\s+\w+\s*\(.*\b(Protocol|ABC)\b`),
+	}
+	rbDeclarations = &Declarations{
+		Functions: regexp.MustCompile(`^\s*def\s`),
+		Types:     regexp.MustCompile(`^\s*(class|module)\s+[A-Z]`),
+	}
+	jsDeclarations = &Declarations{
+		Functions:    regexp.MustCompile(`^\s*(export\s+(default\s+)?)?(async\s+)?function\b|^\s*(export\s+)?(const|let|var)\s+\w+\s*=\s*(async\s+)?(function\b|(\([^)]*\)|\w+)\s*=>)|` + jsMethod),
+		Types:        regexp.MustCompile(`^\s*(export\s+(default\s+)?)?class\b`),
+		NotFunctions: regexp.MustCompile(`^\s*(if|for|while|switch|catch|with)\s*\(`),
+	}
+	tsDeclarations = &Declarations{
+		Functions:    jsDeclarations.Functions,
+		NotFunctions: jsDeclarations.NotFunctions,
+		Types:        regexp.MustCompile(`^\s*(export\s+(default\s+)?)?(declare\s+)?((abstract\s+)?class\b|(const\s+)?enum\s+\w+|type\s+\w+\s*(<[^>]*>)?\s*=)`),
+		Interfaces:   regexp.MustCompile(`^\s*(export\s+)?(declare\s+)?interface\s+\w+`),
+	}
+	javaDeclarations = &Declarations{
+		Functions:    regexp.MustCompile(`^\s*(` + javaModifiers + `+(<[^>]+>\s+)?([\w.<>\[\],?]+\s+)?|(<[^>]+>\s+)?[\w.<>\[\],?]+\s+)\w+\s*\(`),
+		Types:        regexp.MustCompile(`^\s*` + javaModifiers + `*(class|enum|record)\s+\w+`),
+		Interfaces:   regexp.MustCompile(`^\s*` + javaModifiers + `*@?interface\s+\w+`),
+		NotFunctions: regexp.MustCompile(`^\s*(return|new|throw|else|if|for|while|switch|catch|do|case|yield|assert)\b`),
+	}
+	csDeclarations = &Declarations{
+		Functions:  regexp.MustCompile(`^\s*` + csharpModifiers + `+([\w.<>\[\],?]+\s+)?\w+\s*(<[^>]+>)?\s*\(`),
+		Types:      regexp.MustCompile(`^\s*` + csharpModifiers + `*(class|struct|enum|record)\s+\w+`),
+		Interfaces: regexp.MustCompile(`^\s*` + csharpModifiers + `*interface\s+\w+`),
+	}
+	cDeclarations = &Declarations{
+		Functions: regexp.MustCompile(cFunction),
+		Types:     regexp.MustCompile(`^\s*(template\s*<.*>\s*)?(typedef\s+)?(struct|union|enum|class)\s+\w+[^;()]*$`),
+	}
+	objcDeclarations = &Declarations{
+		Functions:  regexp.MustCompile(`^[-+]\s*\(|` + cFunction),
+		Types:      regexp.MustCompile(`^@interface\s+\w+|^(typedef\s+)?(struct|union|enum)\s+\w+[^;()]*$`),
+		Interfaces: regexp.MustCompile(`^@protocol\s+\w+[^;]*$`),
+	}
+	phpDeclarations = &Declarations{
+		Functions:  regexp.MustCompile(`^\s*((public|private|protected|static|abstract|final)\s+)*function\s+&?\w+`),
+		Types:      regexp.MustCompile(`^\s*((abstract|final|readonly)\s+)*(class|trait|enum)\s+\w+`),
+		Interfaces: regexp.MustCompile(`^\s*interface\s+\w+`),
+	}
+	swiftDeclarations = &Declarations{
+		Functions:  regexp.MustCompile(`^\s*(@\w+\s+)*(\w+\s+)*func\s`),
+		Types:      regexp.MustCompile(`^\s*(@\w+\s+)*(\w+\s+)*(class|struct|enum|actor)\s+\w+`),
+		Interfaces: regexp.MustCompile(`^\s*(@\w+\s+)*(\w+\s+)*protocol\s+\w+`),
+	}
+	ktDeclarations = &Declarations{
+		Functions:  regexp.MustCompile(`^\s*(\w+\s+)*fun\s`),
+		Types:      regexp.MustCompile(`^\s*(\w+\s+)*(class|object)\s+\w+`),
+		Interfaces: regexp.MustCompile(`^\s*(\w+\s+)*interface\s+\w+`),
+	}
+	rsDeclarations = &Declarations{
+		Functions:  regexp.MustCompile(`^\s*(pub(\([^)]*\))?\s+)?((const|async|unsafe|extern(\s+"[^"]*")?)\s+)*fn\s+\w+`),
+		Types:      regexp.MustCompile(`^\s*(pub(\([^)]*\))?\s+)?(struct|enum|union|type)\s+\w+`),
+		Interfaces: regexp.MustCompile(`^\s*(pub(\([^)]*\))?\s+)?(unsafe\s+)?trait\s+\w+`),
+	}
+	scalaDeclarations = &Declarations{
+		Functions:  regexp.MustCompile(`^\s*(\w+\s+)*def\s`),
+		Types:      regexp.MustCompile(`^\s*(\w+\s+)*(class|object)\s+\w+`),
+		Interfaces: regexp.MustCompile(`^\s*(\w+\s+)*trait\s+\w+`),
+	}
+	shDeclarations = &Declarations{
+		Functions: regexp.MustCompile(`^\s*(function\s+[\w:.-]+|[\w:.-]+\s*\(\s*\))`),
+	}
+	luaDeclarations = &Declarations{
+		Functions: regexp.MustCompile(`^\s*(local\s+)?function\b|=\s*function\b`),
+	}
+	plDeclarations = &Declarations{
+		Functions: regexp.MustCompile(`^\s*sub\s+\w+`),
+		Types:     regexp.MustCompile(`^\s*package\s+[\w:]+`),
+	}
+)
+
+// trackDeclaration counts a declaration on the current code line, and the
+// line itself towards the length of the function it belongs to. A function
+// runs until the next declaration or the end of the file.
+func (c *lineCounter) trackDeclaration() {
+	decls := c.lang.Declarations
+	if decls == nil {
+		return
+	}
+
+	kind := declNone
+	if !c.inHeredoc && !c.lineInString {
+		kind = decls.match(c.head)
+	}
+	switch kind {
+	case declFunction:
+		c.stats.Functions++
+		c.inFunction = true
+	case declType:
+		c.stats.Types++
+		c.inFunction = false
+	case declInterface:
+		c.stats.Interfaces++
+		c.inFunction = false
+	}
+
+	if c.inFunction {
+		c.stats.FunctionLines++
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCountLinesDeclarations(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name              string
+		filename          string
+		content           string
+		wantFunctions     int
+		wantTypes         int
+		wantInterfaces    int
+		wantFunctionLines int
+	}{
+		{
+			name:     "Go",
+			filename: "shapes.go",
+			content: `package shapes
+
+type Shape interface {
+	Area() float64
+}
+
+type Square struct{ side float64 }
+
+// Area returns the area
+func (s Square) Area() float64 {
+	return s.side * s.side
+}
+
+func describe() string {
+	return ` + "`" + `
+func notReal() {}
+` + "`" + `
+}
+`,
+			wantFunctions:     2,
+			wantTypes:         1,
+			wantInterfaces:    1,
+			wantFunctionLines: 8,
+		},
+		{
+			name:              "Python",
+			filename:          "models.py",
+			content:           "class Shape(Protocol):\n    def area(self): ...\n\nclass Square:\n    def __init__(self, side):\n        self.side = side\n\n    async def area(self):\n        return self.side ** 2\n",
+			wantFunctions:     3,
+			wantTypes:         1,
+			wantInterfaces:    1,
+			wantFunctionLines: 5,
+		},
+		{
+			name:              "Java",
+			filename:          "Square.java",
+			content:           "public class Square implements Shape {\n    private final double side;\n\n    public Square(double side) {\n        this.side = side;\n    }\n\n    @Override\n    public double area() {\n        if (side > 0) {\n            return side * side;\n        }\n        return 0;\n    }\n}\ninterface Shape { double area(); }\n",
+			wantFunctions:     2,
+			wantTypes:         1,
+			wantInterfaces:    1,
+			wantFunctionLines: 11,
+		},
+		{
+			name:              "Java package-private methods",
+			filename:          "Worker.java",
+			content:           "class Worker {\n    void run() {\n        if (ready()) {\n            return compute(items);\n        }\n        throw new IllegalStateException(\"stopped\");\n    }\n\n    static <T> List<T> wrap(T item) {\n        return List.of(item);\n    }\n}\n",
+			wantFunctions:     2,
+			wantTypes:         1,
+			wantFunctionLines: 10,
+		},
+		{
+			name:              "C definitions start at column 0",
+			filename:          "util.c",
+			content:           "struct point {\n    int x, y;\n};\n\nstatic int add(int a, int b)\n{\n    return helper(a, b);\n}\n\nint helper(int a, int b);\n",
+			wantFunctions:     1,
+			wantTypes:         1,
+			wantFunctionLines: 5,
+		},
+		{
+			name:              "TypeScript",
+			filename:          "api.ts",
+			content:           "export interface User { id: number }\nexport type Id = number;\nexport const load = async (id: Id) => {\n  return fetch(id);\n};\nexport function save(user: User) {}\n",
+			wantFunctions:     2,
+			wantTypes:         1,
+			wantInterfaces:    1,
+			wantFunctionLines: 4,
+		},
+		{
+			name:              "TypeScript class methods",
+			filename:          "queue.ts",
+			content:           "class Queue {\n  constructor(items) {\n    this.items = items;\n  }\n\n  async drain(): Promise<void> {\n    for (const item of this.items) {\n      await this.handle(item);\n    }\n    if (this.items.length) {\n      this.items = [];\n    }\n  }\n\n  static get size() { return 0; }\n}\n",
+			wantFunctions:     3,
+			wantTypes:         1,
+			wantFunctionLines: 13,
+		},
+		{
+			name:              "Shell",
+			filename:          "deploy.sh",
+			content:           "build() {\n  make\n}\nfunction ship {\n  scp out host:\n}\nbuild\n",
+			wantFunctions:     2,
+			wantFunctionLines: 7,
+		},
+		{
+			name:     "Languages without patterns",
+			filename: "config.yaml",
+			content:  "func: x\nclass: y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, Languages[filepath.Ext(tt.filename)])
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			if stats.Functions != tt.wantFunctions {
+				t.Errorf("Functions = %d, want %d", stats.Functions, tt.wantFunctions)
+			}
+			if stats.Types != tt.wantTypes {
+				t.Errorf("Types = %d, want %d", stats.Types, tt.wantTypes)
+			}
+			if stats.Interfaces != tt.wantInterfaces {
+				t.Errorf("Interfaces = %d, want %d", stats.Interfaces, tt.wantInterfaces)
+			}
+			if stats.FunctionLines != tt.wantFunctionLines {
+				t.Errorf("FunctionLines = %d, want %d", stats.FunctionLines, tt.wantFunctionLines)
+			}
+		})
+	}
+}
+
+func TestAverageFunctionLength(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats LanguageStats
+		want  float64
+	}{
+		{"No functions", LanguageStats{FunctionLines: 10}, 0},
+		{"Mean length", LanguageStats{Functions: 4, FunctionLines: 30}, 7.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.AverageFunctionLength(); got != tt.want {
+				t.Errorf("AverageFunctionLength() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxShebangLength caps how much of a file is read to find its interpreter
+const maxShebangLength = 256
+
+// DetectOptions configures how the language of a file is resolved
+type DetectOptions struct {
+	// Fallbacks maps an ambiguous extension such as ".h" to the name of
+	// the language used when no content heuristic matches
+	Fallbacks map[string]string
+}
+
+// DetectLanguage resolves the language of a file from its extension, its
+// content when the extension is ambiguous, its name and finally the
+// interpreter named on its shebang line
+func DetectLanguage(path string, opts DetectOptions) *Language {
+	return detectLanguage(path, readHead(path, maxHeuristicLength), opts)
+}
+
+// detectLanguage is DetectLanguage for a file whose first bytes, at least
+// maxHeuristicLength of them, were already read
+func detectLanguage(path string, head []byte, opts DetectOptions) *Language {
+	ext := filepath.Ext(path)
+	if candidates, ok := Heuristics[strings.ToLower(ext)]; ok {
+		content := decodeHead(head, maxHeuristicLength)
+		return resolveHeuristics(strings.ToLower(ext), content, candidates, opts)
+	}
+
+	lang := GetLanguage(strings.ToLower(ext))
+	if lang == nil {
+		// Try case-sensitive lookup for extensions like .R
+		lang = GetLanguage(ext)
+	}
+	if lang == nil {
+		lang = GetLanguageByFilename(filepath.Base(path))
+	}
+	if lang == nil {
+		lang = GetLanguageByShebang(shebangLine(head))
+	}
+	return lang
+}
+
+// readHead returns up to limit bytes from the start of a file
+func readHead(path string, limit int) []byte {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	head := make([]byte, limit)
+	n, _ := io.ReadFull(file, head)
+	return head[:n]
+}
+
+// decodeHead decodes the first bytes of a file to UTF-8 without a byte
+// order mark, returning up to limit bytes of text
+func decodeHead(head []byte, limit int) string {
+	text, _ := decodeText(bytes.NewReader(head))
+	buf := make([]byte, limit)
+	n, _ := io.ReadFull(text, buf)
+	return string(buf[:n])
+}
+
+// shebangLine returns the first line of a file if it starts with #!
+func shebangLine(head []byte) string {
+	text := decodeHead(head, maxShebangLength)
+	if !strings.HasPrefix(text, "#!") {
+		return ""
+	}
+	line, _, _ := strings.Cut(text, "\n")
+	return strings.TrimSuffix(line, "\r")
+}
+
+// GetLanguageByShebang returns the language for the interpreter named in a
+// shebang line such as "#!/usr/bin/env -S python3 -u"
+func GetLanguageByShebang(line string) *Language {
+	interpreter := shebangInterpreter(line)
+	if interpreter == "" {
+		return nil
+	}
+	if ext, ok := InterpreterLanguages[interpreter]; ok {
+		return GetLanguage(ext)
+	}
+	return nil
+}
+
+// shebangInterpreter extracts the interpreter name from a shebang line,
+// skipping env and its options and dropping version suffixes
+func shebangInterpreter(line string) string {
+	rest, ok := strings.CutPrefix(line, "#!")
+	if !ok {
+		return ""
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	name := filepath.Base(fields[0])
+	if name == "env" {
+		name = ""
+		skipArg := false
+		for _, field := range fields[1:] {
+			if skipArg {
+				skipArg = false
+				continue
+			}
+			if split, ok := strings.CutPrefix(field, "-S"); ok && split != "" {
+				// env -Spython3 packs the command into the option
+				field = split
+			} else if field == "-u" || field == "-C" {
+				// These options take a separate argument
+				skipArg = true
+				continue
+			} else if strings.HasPrefix(field, "-") || strings.Contains(field, "=") {
+				continue
+			}
+			name = filepath.Base(field)
+			break
+		}
+	}
+
+	name = strings.ToLower(name)
+	return strings.TrimRight(name, "0123456789.-")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShebangInterpreter(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"#!/bin/bash", "bash"},
+		{"#!/bin/sh -e", "sh"},
+		{"#! /usr/bin/python3.11", "python"},
+		{"#!/usr/bin/env python3", "python"},
+		{"#!/usr/bin/env node", "node"},
+		{"#!/usr/bin/env -S deno run --allow-net", "deno"},
+		{"#!/usr/bin/env -Spython3 -u", "python"},
+		{"#!/usr/bin/env -i PATH=/bin perl5.36 -w", "perl"},
+		{"#!/usr/bin/env -u HOME ruby", "ruby"},
+		{"#!/usr/local/bin/Rscript", "rscript"},
+		{"#!", ""},
+		{"# not a shebang", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := shebangInterpreter(tt.line); got != tt.want {
+				t.Errorf("shebangInterpreter(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "detect-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		filename string
+		content  string
+		want     string
+	}{
+		{"main.go", "package main\n", "Go"},
+		{"analysis.R", "x <- 1\n", "R"},
+		{"Makefile", "all:\n", "Makefile"},
+		{"deploy", "#!/bin/bash\necho hi\n", "Shell"},
+		{"manage", "#!/usr/bin/env python3\nprint(1)\n", "Python"},
+		{"server", "#!/usr/bin/env node\nconsole.log(1)\n", "JavaScript"},
+		{"notes", "just some text\n", ""},
+		{"tool", "#!/opt/bin/unknown-interpreter\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			lang := DetectLanguage(filePath, DetectOptions{})
+			got := ""
+			if lang != nil {
+				got = lang.Name
+			}
+			if got != tt.want {
+				t.Errorf("DetectLanguage(%q) = %q, want %q", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Text encodings recorded in FileStats.Encoding
+const (
+	EncodingUTF8    = "UTF-8"
+	EncodingUTF8BOM = "UTF-8 BOM"
+	EncodingUTF16LE = "UTF-16LE"
+	EncodingUTF16BE = "UTF-16BE"
+)
+
+// Line ending styles recorded in FileStats.LineEndings
+const (
+	LineEndingsNone  = ""
+	LineEndingsLF    = "LF"
+	LineEndingsCRLF  = "CRLF"
+	LineEndingsCR    = "CR"
+	LineEndingsMixed = "Mixed"
+)
+
+// encodingSampleSize is how many bytes are inspected to spot UTF-16 text
+// that has no byte order mark
+const encodingSampleSize = 512
+
+// decodeText detects the encoding of r from its byte order mark or, failing
+// that, from the pattern of zero bytes UTF-16 leaves in ASCII text. It
+// returns a reader producing UTF-8 without the byte order mark.
+func decodeText(r io.Reader) (io.Reader, string) {
+	reader := bufio.NewReader(r)
+	head, _ := reader.Peek(encodingSampleSize)
+
+	switch {
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		reader.Discard(3)
+		return reader, EncodingUTF8BOM
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		reader.Discard(2)
+		return &utf16Reader{r: reader, order: binary.LittleEndian}, EncodingUTF16LE
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		reader.Discard(2)
+		return &utf16Reader{r: reader, order: binary.BigEndian}, EncodingUTF16BE
+	}
+
+	switch guessUTF16(head) {
+	case EncodingUTF16LE:
+		return &utf16Reader{r: reader, order: binary.LittleEndian}, EncodingUTF16LE
+	case EncodingUTF16BE:
+		return &utf16Reader{r: reader, order: binary.BigEndian}, EncodingUTF16BE
+	}
+	return reader, EncodingUTF8
+}
+
+// guessUTF16 reports UTF-16 when most code units in the sample have a zero
+// high byte, as they do for ASCII text, and few have a zero low byte
+func guessUTF16(sample []byte) string {
+	units := len(sample) / 2
+	if units == 0 {
+		return ""
+	}
+
+	evenZeros, oddZeros := 0, 0
+	for i := 0; i+1 < len(sample); i += 2 {
+		if sample[i] == 0 {
+			evenZeros++
+		}
+		if sample[i+1] == 0 {
+			oddZeros++
+		}
+	}
+
+	switch {
+	case oddZeros*2 > units && evenZeros*8 < oddZeros:
+		return EncodingUTF16LE
+	case evenZeros*2 > units && oddZeros*8 < evenZeros:
+		return EncodingUTF16BE
+	}
+	return ""
+}
+
+// utf16Reader decodes UTF-16 code units into UTF-8
+type utf16Reader struct {
+	r       *bufio.Reader
+	order   binary.ByteOrder
+	pending []byte
+}
+
+// Read fills p with UTF-8 text decoded from the underlying reader
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(u.pending) > 0 {
+			copied := copy(p[n:], u.pending)
+			u.pending = u.pending[copied:]
+			n += copied
+			continue
+		}
+
+		r, err := u.readRune()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		u.pending = utf8.AppendRune(u.pending[:0], r)
+	}
+	return n, nil
+}
+
+// readRune decodes one character, joining surrogate pairs
+func (u *utf16Reader) readRune() (rune, error) {
+	unit, err := u.readUnit()
+	if err != nil {
+		return 0, err
+	}
+
+	r := rune(unit)
+	if utf16.IsSurrogate(r) {
+		next, err := u.r.Peek(2)
+		if err == nil {
+			if low := rune(u.order.Uint16(next)); utf16.IsSurrogate(low) {
+				u.r.Discard(2)
+				return utf16.DecodeRune(r, low), nil
+			}
+		}
+		return utf8.RuneError, nil
+	}
+	return r, nil
+}
+
+// readUnit reads one 16-bit code unit, dropping a trailing odd byte
+func (u *utf16Reader) readUnit() (uint16, error) {
+	var unit [2]byte
+	if _, err := io.ReadFull(u.r, unit[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return 0, err
+	}
+	return u.order.Uint16(unit[:]), nil
+}
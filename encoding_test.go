@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+// encodeUTF16 encodes s as UTF-16 in the given byte order, optionally with
+// a byte order mark
+func encodeUTF16(s string, order binary.AppendByteOrder, bom bool) []byte {
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xFEFF}, units...)
+	}
+	out := make([]byte, 0, len(units)*2)
+	for _, unit := range units {
+		out = order.AppendUint16(out, unit)
+	}
+	return out
+}
+
+func TestCountLinesEncoding(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	const source = "// comment\nint x = 1;\n\nint y = 2; // é 😀\n"
+
+	tests := []struct {
+		name            string
+		content         []byte
+		wantEncoding    string
+		wantLineEndings string
+		wantComment     int
+		wantCode        int
+		wantBlank       int
+	}{
+		{"UTF-8", []byte(source), EncodingUTF8, LineEndingsLF, 1, 2, 1},
+		{"UTF-8 BOM", append([]byte{0xEF, 0xBB, 0xBF}, source...), EncodingUTF8BOM, LineEndingsLF, 1, 2, 1},
+		{"UTF-16LE BOM", encodeUTF16(source, binary.LittleEndian, true), EncodingUTF16LE, LineEndingsLF, 1, 2, 1},
+		{"UTF-16BE BOM", encodeUTF16(source, binary.BigEndian, true), EncodingUTF16BE, LineEndingsLF, 1, 2, 1},
+		{"UTF-16LE without BOM", encodeUTF16(source, binary.LittleEndian, false), EncodingUTF16LE, LineEndingsLF, 1, 2, 1},
+		{"UTF-16BE without BOM", encodeUTF16(source, binary.BigEndian, false), EncodingUTF16BE, LineEndingsLF, 1, 2, 1},
+		{"CRLF", []byte("// comment\r\nint x = 1;\r\n\r\n"), EncodingUTF8, LineEndingsCRLF, 1, 1, 1},
+		{"CR only", []byte("// comment\rint x = 1;\r\rint y = 2;\r"), EncodingUTF8, LineEndingsCR, 1, 2, 1},
+		{"Mixed endings", []byte("// comment\r\nint x = 1;\rint y = 2;\n"), EncodingUTF8, LineEndingsMixed, 1, 2, 0},
+		{"UTF-16LE CRLF", encodeUTF16("// comment\r\nint x = 1;\r\n", binary.LittleEndian, true), EncodingUTF16LE, LineEndingsCRLF, 1, 1, 0},
+		{"No line ending", []byte("int x = 1;"), EncodingUTF8, LineEndingsNone, 0, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, "test.c")
+			if err := os.WriteFile(filePath, tt.content, 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, Languages[".c"])
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			if stats.Encoding != tt.wantEncoding {
+				t.Errorf("Encoding = %q, want %q", stats.Encoding, tt.wantEncoding)
+			}
+			if stats.LineEndings != tt.wantLineEndings {
+				t.Errorf("LineEndings = %q, want %q", stats.LineEndings, tt.wantLineEndings)
+			}
+			if stats.CommentLines != tt.wantComment {
+				t.Errorf("CommentLines = %d, want %d", stats.CommentLines, tt.wantComment)
+			}
+			if stats.CodeLines != tt.wantCode {
+				t.Errorf("CodeLines = %d, want %d", stats.CodeLines, tt.wantCode)
+			}
+			if stats.BlankLines != tt.wantBlank {
+				t.Errorf("BlankLines = %d, want %d", stats.BlankLines, tt.wantBlank)
+			}
+		})
+	}
+}
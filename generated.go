@@ -0,0 +1,152 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// generatedHeaderLines is how many lines at the top of a file are searched
+// for a generated-code marker
+const generatedHeaderLines = 30
+
+// commentPrefix matches the opening of a line or block comment, or the
+// leader of a block comment's inner lines
+const commentPrefix = `(//+|#+|--+|;+|/?\*+|<!--|\(\*|%+|')`
+
+// generatedMarker matches the comments code generators leave in their
+// output, such as Go's "// Code generated by protoc-gen-go. DO NOT EDIT."
+// The marker must open the comment, so that documentation quoting it, like
+// this comment, is not taken for one. A notice that the file is
+// auto-generated and not to be edited may be anywhere in a comment, but not
+// in a string or quoted.
+var generatedMarker = regexp.MustCompile(`^\s*` + commentPrefix + `?\s*(Code generated .*DO NOT EDIT|@generated\b|<auto-generated)|` +
+	`^\s*` + commentPrefix + `(?i:[^"'\x60]*\bauto-?generated\b.*\bdo not (edit|modify)\b[^"'\x60]*$)`)
+
+// GeneratedFilePatterns lists names of files written by code generators
+var GeneratedFilePatterns = []string{
+	"*.pb.go",
+	"*.pb.gw.go",
+	"*_pb2.py",
+	"*_pb2_grpc.py",
+	"*_pb2.pyi",
+	"*.pb.cc",
+	"*.pb.h",
+	"*_pb.js",
+	"*_pb.d.ts",
+	"*_grpc_pb.js",
+	"*.g.dart",
+	"*.freezed.dart",
+	"*.designer.cs",
+	"*.g.cs",
+}
+
+// MinifiedFilePatterns lists names used for minified files
+var MinifiedFilePatterns = []string{
+	"*.min.js",
+	"*.min.mjs",
+	"*.min.css",
+}
+
+const (
+	// minifiedMinBytes keeps small files, such as one-line configs, from
+	// being taken for minified ones
+	minifiedMinBytes = 1024
+
+	// minifiedLineLength is the average line length above which a file
+	// may be minified
+	minifiedLineLength = 300
+
+	// minifiedWhitespace is the share of whitespace below which a file
+	// with long lines is minified
+	minifiedWhitespace = 0.1
+)
+
+// generatedSink passes lines through to a counter while looking for a
+// generated-code marker in the header of the file
+type generatedSink struct {
+	lineSink
+	lines int
+	found bool
+}
+
+func (g *generatedSink) beginLine(head string) {
+	if g.lines < generatedHeaderLines && !g.found && generatedMarker.MatchString(head) {
+		g.found = true
+	}
+	g.lines++
+	g.lineSink.beginLine(head)
+}
+
+// isGeneratedName reports whether a file name is one code generators use
+func isGeneratedName(path string) bool {
+	return matchesAnyPattern(path, GeneratedFilePatterns)
+}
+
+// isMinified reports whether a file is minified, from its name or from
+// lines that are long on average and nearly free of whitespace
+func isMinified(path string, shape textShape, totalLines int) bool {
+	if matchesAnyPattern(path, MinifiedFilePatterns) {
+		return true
+	}
+	if shape.bytes < minifiedMinBytes || totalLines == 0 {
+		return false
+	}
+	averageLine := shape.bytes / totalLines
+	return averageLine > minifiedLineLength && float64(shape.whitespace) < minifiedWhitespace*float64(shape.bytes)
+}
+
+// matchesAnyPattern reports whether the base name of path matches one of
+// the glob patterns, ignoring case
+func matchesAnyPattern(path string, patterns []string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for _, pattern := range patterns {
+		if match, err := filepath.Match(pattern, name); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+// FileGroup is a set of files reported apart from the main totals
+type FileGroup struct {
+	Name      string
+	LangStats map[string]*LanguageStats
+	Total     *LanguageStats
+}
+
+// SplitExcluded separates generated and minified files from the others,
+// unless they are to be included in the main totals. Generated takes
+// precedence for files that are both. Groups without files are omitted.
+func SplitExcluded(fileStats []*FileStats, includeGenerated, includeMinified bool) ([]*FileStats, []FileGroup) {
+	var counted, generated, minified []*FileStats
+	for _, fs := range fileStats {
+		switch {
+		case fs == nil:
+			continue
+		case fs.Generated && !includeGenerated:
+			generated = append(generated, fs)
+		case fs.Minified && !includeMinified && !fs.Generated:
+			minified = append(minified, fs)
+		default:
+			counted = append(counted, fs)
+		}
+	}
+
+	var groups []FileGroup
+	for _, group := range []struct {
+		name  string
+		files []*FileStats
+	}{{"Generated", generated}, {"Minified", minified}} {
+		if len(group.files) == 0 {
+			continue
+		}
+		langStats := AggregateStats(group.files)
+		groups = append(groups, FileGroup{
+			Name:      group.name,
+			LangStats: langStats,
+			Total:     TotalStats(langStats),
+		})
+	}
+	return counted, groups
+}
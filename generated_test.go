@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountLinesGeneratedAndMinified(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	bundle := "var a=1;" + strings.Repeat("function f(){return 1};", 100) + "\n"
+	prose := strings.Repeat("word ", 100) + "\n"
+
+	tests := []struct {
+		name          string
+		filename      string
+		content       string
+		ext           string
+		wantGenerated bool
+		wantMinified  bool
+	}{
+		{"Go generator marker", "mock_store.go", "// Code generated by MockGen. DO NOT EDIT.\npackage mocks\n", ".go", true, false},
+		{"Marker after a license header", "api.go", "// Copyright 2024\n\n// Code generated by ent, DO NOT EDIT.\npackage ent\n", ".go", true, false},
+		{"@generated marker", "Schema.java", "/* @generated */\nclass Schema {}\n", ".java", true, false},
+		{"Quoted marker is ignored", "doc.go", "// Files marked \"Code generated ... DO NOT EDIT.\" are skipped\npackage doc\n", ".go", false, false},
+		{"Marker in the body is ignored", "gen.go", strings.Repeat("var x = 1\n", generatedHeaderLines) + "// Code generated by x. DO NOT EDIT.\n", ".go", false, false},
+		{"Auto-generated comment", "client.ts", "/* This file is auto-generated, do not modify */\nexport {}\n", ".ts", true, false},
+		{"Auto-generated phrase in a string", "msg.js", "const s = \"This file is auto-generated, do not edit\"\n", ".js", false, false},
+		{"Quoted notice across comment lines", "note.go", "// Files saying \"This file is\n// auto-generated, do not edit\" are skipped\npackage note\n", ".go", false, false},
+		{"Protobuf Go name", "user.pb.go", "package user\n", ".go", true, false},
+		{"Protobuf Python name", "user_pb2.py", "import sys\n", ".py", true, false},
+		{"Hand-written file", "main.go", "package main\n\n// Generated IDs are unique\nfunc main() {}\n", ".go", false, false},
+		{"Minified bundle", "app.js", bundle, ".js", false, true},
+		{"Minified name", "vendor.min.js", "var a = 1;\n", ".js", false, true},
+		{"Long prose lines", "notes.js", prose + prose + prose, ".js", false, false},
+		{"Small one-liner", "one.js", "var a=1;\n", ".js", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, Languages[tt.ext])
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			if stats.Generated != tt.wantGenerated {
+				t.Errorf("Generated = %v, want %v", stats.Generated, tt.wantGenerated)
+			}
+			if stats.Minified != tt.wantMinified {
+				t.Errorf("Minified = %v, want %v", stats.Minified, tt.wantMinified)
+			}
+		})
+	}
+}
+
+func TestSplitExcluded(t *testing.T) {
+	fileStats := []*FileStats{
+		{Language: "Go", CodeLines: 10, TotalLines: 10},
+		{Language: "Go", CodeLines: 100, TotalLines: 100, Generated: true},
+		{Language: "JavaScript", CodeLines: 1, TotalLines: 1, Minified: true},
+		{Language: "JavaScript", CodeLines: 2, TotalLines: 2, Generated: true, Minified: true},
+		nil,
+	}
+
+	tests := []struct {
+		name             string
+		includeGenerated bool
+		includeMinified  bool
+		wantCounted      int
+		wantGroups       []string
+	}{
+		{"Exclude both", false, false, 1, []string{"Generated", "Minified"}},
+		{"Include generated", true, false, 3, []string{"Minified"}},
+		{"Include minified", false, true, 2, []string{"Generated"}},
+		{"Include both", true, true, 4, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counted, groups := SplitExcluded(fileStats, tt.includeGenerated, tt.includeMinified)
+			if len(counted) != tt.wantCounted {
+				t.Errorf("len(counted) = %d, want %d", len(counted), tt.wantCounted)
+			}
+			var names []string
+			for _, group := range groups {
+				names = append(names, group.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantGroups, ",") {
+				t.Errorf("groups = %v, want %v", names, tt.wantGroups)
+			}
+		})
+	}
+
+	_, groups := SplitExcluded(fileStats, false, false)
+	if got := groups[0].Total.CodeLines; got != 102 {
+		t.Errorf("Generated CodeLines = %d, want 102", got)
+	}
+}
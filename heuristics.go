@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxHeuristicLength caps how much of an ambiguous file is inspected
+const maxHeuristicLength = 8 * 1024
+
+// Heuristic describes one candidate language for an ambiguous extension
+type Heuristic struct {
+	Language string
+
+	// Modelines lists the names an editor modeline uses for the language,
+	// as in "vim: set ft=objc:" or "-*- mode: matlab -*-"
+	Modelines []string
+
+	// Pattern matches content that only this candidate would contain
+	Pattern *regexp.Regexp
+}
+
+// Heuristics lists, for each ambiguous extension, the candidate languages
+// in the order their patterns are tried. When nothing matches, the entry
+// in Languages for the extension is used unless a fallback is configured.
+var Heuristics = map[string][]Heuristic{
+	".h": {
+		{
+			Language:  "Objective-C",
+			Modelines: []string{"objc", "objective-c"},
+			Pattern:   regexp.MustCompile(`(?m)^\s*(@(interface|protocol|class|property|end)\b|#import\s)`),
+		},
+		{
+			Language:  "C++ Header",
+			Modelines: []string{"cpp", "c++"},
+			Pattern:   regexp.MustCompile(`(?m)^\s*(template\s*<|namespace\s+\w+|class\s+\w+\s*(final\s*)?[:{]|(public|private|protected)\s*:|#include\s*<(iostream|string|vector|memory|map|algorithm)>)`),
+		},
+		{
+			Language:  "C Header",
+			Modelines: []string{"c"},
+		},
+	},
+	".m": {
+		{
+			Language:  "Objective-C",
+			Modelines: []string{"objc", "objective-c"},
+			Pattern:   regexp.MustCompile(`(?m)^\s*(@(interface|implementation|protocol|end|import)\b|#(import|include)\s)`),
+		},
+		{
+			Language:  "MATLAB",
+			Modelines: []string{"matlab", "octave"},
+			Pattern:   regexp.MustCompile(`(?m)^\s*(function\s.*\w|classdef\s|%.*$|end\s*$)`),
+		},
+	},
+	".pl": {
+		{
+			Language:  "Perl",
+			Modelines: []string{"perl"},
+			Pattern:   regexp.MustCompile(`(?m)^\s*(use\s+(strict|warnings|v?5)|package\s+[\w:]+;|sub\s+\w+|my\s+[$@%])`),
+		},
+		{
+			Language:  "Prolog",
+			Modelines: []string{"prolog"},
+			Pattern:   regexp.MustCompile(`(?m)^\s*(:-|[a-z]\w*(\([^)]*\))?\s*:-)`),
+		},
+	},
+	".v": {
+		{
+			Language:  "Verilog",
+			Modelines: []string{"verilog"},
+			Pattern:   regexp.MustCompile("(?m)^\\s*(module\\s+\\w+\\s*[(#;]|endmodule\\b|always\\s*@|`(timescale|define|include)\\b)"),
+		},
+		{
+			Language:  "V",
+			Modelines: []string{"v", "vlang"},
+			Pattern:   regexp.MustCompile(`(?m)^\s*(fn\s+\w+|module\s+\w+\s*$|import\s+\w+\s*$|pub\s+(fn|struct)\s)`),
+		},
+	},
+	".ts": {
+		{
+			Language:  "Qt Translation",
+			Modelines: []string{"xml"},
+			Pattern:   regexp.MustCompile(`<!DOCTYPE TS>|<TS\s`),
+		},
+		{
+			Language:  "TypeScript",
+			Modelines: []string{"typescript"},
+			Pattern:   regexp.MustCompile(`(?m)^\s*(import|export|const|let|function|interface|type|class)\b`),
+		},
+	},
+}
+
+// modelinePattern finds the file type named in a vim or emacs modeline
+var modelinePattern = regexp.MustCompile(`(?i)(?:-\*-.*?\bmode:\s*([\w+#-]+)|-\*-\s*([\w+#-]+)\s*-\*-|\bvim?:.*?\b(?:ft|filetype|syntax)=([\w+#-]+))`)
+
+// resolveHeuristics picks a candidate language for an ambiguous extension
+// from a modeline, then from content patterns, then from the fallback
+func resolveHeuristics(ext, content string, candidates []Heuristic, opts DetectOptions) *Language {
+	if mode := modeline(content); mode != "" {
+		for _, candidate := range candidates {
+			for _, name := range candidate.Modelines {
+				if strings.EqualFold(name, mode) {
+					return GetLanguageByName(candidate.Language)
+				}
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		if candidate.Pattern != nil && candidate.Pattern.MatchString(content) {
+			return GetLanguageByName(candidate.Language)
+		}
+	}
+
+	if name, ok := opts.Fallbacks[ext]; ok {
+		return GetLanguageByName(name)
+	}
+	return GetLanguage(ext)
+}
+
+// modeline returns the file type named in an editor modeline, if any
+func modeline(content string) string {
+	match := modelinePattern.FindStringSubmatch(content)
+	if match == nil {
+		return ""
+	}
+	for _, group := range match[1:] {
+		if group != "" {
+			return group
+		}
+	}
+	return ""
+}
+
+// ValidateFallbacks reports a fallback that names an extension without
+// heuristics or a language that is not one of its candidates
+func ValidateFallbacks(fallbacks map[string]string) error {
+	for ext, name := range fallbacks {
+		candidates, ok := Heuristics[ext]
+		if !ok {
+			return fmt.Errorf("no content heuristics for extension %q", ext)
+		}
+		found := false
+		for _, candidate := range candidates {
+			if candidate.Language == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%q is not a candidate language for %q", name, ext)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectLanguageHeuristics(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "heuristics-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name      string
+		filename  string
+		content   string
+		fallbacks map[string]string
+		want      string
+	}{
+		{"C header", "util.h", "#ifndef UTIL_H\nint add(int a, int b);\n#endif\n", nil, "C Header"},
+		{"C++ header", "vec.h", "#include <vector>\nnamespace geo {\n}\n", nil, "C++ Header"},
+		{"Objective-C header", "View.h", "#import <UIKit/UIKit.h>\n@interface View : UIView\n@end\n", nil, "Objective-C"},
+		{"Objective-C source", "View.m", "#import \"View.h\"\n@implementation View\n@end\n", nil, "Objective-C"},
+		{"MATLAB function", "area.m", "function a = area(r)\n  a = pi * r^2;\nend\n", nil, "MATLAB"},
+		{"Perl script", "tool.pl", "use strict;\nmy $x = 1;\n", nil, "Perl"},
+		{"Prolog facts", "family.pl", "parent(tom, bob).\nancestor(X, Y) :- parent(X, Y).\n", nil, "Prolog"},
+		{"Verilog module", "counter.v", "module counter(input clk);\nendmodule\n", nil, "Verilog"},
+		{"V program", "main.v", "module main\n\nfn main() {\n}\n", nil, "V"},
+		{"Qt translation", "app_de.ts", "<?xml version=\"1.0\"?>\n<!DOCTYPE TS>\n<TS version=\"2.1\">\n</TS>\n", nil, "Qt Translation"},
+		{"TypeScript", "index.ts", "export const x = 1;\n", nil, "TypeScript"},
+		{"Modeline wins", "odd.h", "// vim: set ft=cpp:\nint x;\n", nil, "C++ Header"},
+		{"Emacs modeline", "odd.m", "% -*- mode: objc -*-\n", nil, "Objective-C"},
+		{"Default without markers", "plain.h", "int x;\n", nil, "C Header"},
+		{"Configured fallback", "plain2.h", "int x;\n", map[string]string{".h": "C++ Header"}, "C++ Header"},
+		{"Content beats fallback", "objc.h", "@protocol P\n@end\n", map[string]string{".h": "C++ Header"}, "Objective-C"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			lang := DetectLanguage(filePath, DetectOptions{Fallbacks: tt.fallbacks})
+			if lang == nil {
+				t.Fatalf("DetectLanguage(%q) = nil, want %q", tt.filename, tt.want)
+			}
+			if lang.Name != tt.want {
+				t.Errorf("DetectLanguage(%q) = %q, want %q", tt.filename, lang.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFallbacks(t *testing.T) {
+	tests := []struct {
+		name      string
+		fallbacks map[string]string
+		wantErr   bool
+	}{
+		{"None", nil, false},
+		{"Valid", map[string]string{".h": "C++ Header", ".m": "MATLAB"}, false},
+		{"Unknown extension", map[string]string{".go": "Go"}, true},
+		{"Not a candidate", map[string]string{".pl": "Python"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFallbacks(tt.fallbacks)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFallbacks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHeuristicCandidatesExist(t *testing.T) {
+	for ext, candidates := range Heuristics {
+		for _, candidate := range candidates {
+			if GetLanguageByName(candidate.Language) == nil {
+				t.Errorf("Heuristics[%q] names unknown language %q", ext, candidate.Language)
+			}
+		}
+		if GetLanguage(ext) == nil {
+			t.Errorf("Heuristics[%q] has no default language in Languages", ext)
+		}
+	}
+}
@@ -1,16 +1,26 @@
 package main
 
-import "sync"
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
 
 var (
 	languageSync           sync.Map
 	fileNameLanguageSync   sync.Map
 	hiddenFileLanguageSync sync.Map
+	nameLanguageSync       sync.Map
 )
 
 func init() {
 	for k, v := range Languages {
 		languageSync.Store(k, v)
+		nameLanguageSync.LoadOrStore(v.Name, v)
+	}
+
+	for k, v := range HeuristicLanguages {
+		nameLanguageSync.Store(k, v)
 	}
 
 	for k, v := range FilenameLanguages {
@@ -25,13 +35,156 @@ func init() {
 
 // Language represents a programming language with its comment patterns
 type Language struct {
-	Name              string
-	Extensions        []string
-	SingleLineComment string
-	MultiLineStart    string
-	MultiLineEnd      string
-	StringDelimiters  []string
-	NestedComments    bool
+	Name           string
+	Extensions     []string
+	LineComments   []string
+	BlockComments  []BlockComment
+	Strings        []StringLiteral
+	Heredocs       []Heredoc
+	NestedComments bool
+
+	// Preprocessor is set for languages run through the C preprocessor,
+	// whose #if 0 blocks can be counted as disabled code
+	Preprocessor bool
+
+	// Branches lists the keywords and operators that add a branch, such as
+	// if and &&, to estimate cyclomatic complexity
+	Branches []string
+
+	// Declarations recognises the code lines that declare functions, types
+	// and interfaces
+	Declarations *Declarations
+
+	// Statements describes how statements are counted as logical lines
+	Statements *Statements
+
+	// MarkerRules restricts where the comment markers named as its keys
+	// are recognised. Markers without a rule match anywhere.
+	MarkerRules map[string]MarkerRule
+
+	// DocStringDelimiters lists string literal starts that are counted as
+	// documentation when they open the first statement of a module or of a
+	// scope introduced by one of DocStringScopes. Anywhere else they are
+	// ordinary (possibly multi-line) string literals.
+	DocStringDelimiters []string
+	DocStringScopes     []string
+
+	// DocComments lists markers that open documentation comments, like
+	// Rust's /// and Javadoc's /**. DocCommentsBefore matches declarations
+	// whose directly preceding comment lines are documentation, like Go's
+	// exported identifiers.
+	DocComments       []string
+	DocCommentsBefore *regexp.Regexp
+
+	// EmbeddedRegions lists elements whose content is written in another
+	// language and is counted under that language
+	EmbeddedRegions []EmbeddedRegion
+
+	// CodeFences lists the markers opening fenced code blocks, whose content
+	// can be counted under the language named after the fence
+	CodeFences []string
+}
+
+// BlockComment is a pair of markers delimiting a comment that may span lines
+type BlockComment struct {
+	Start string
+	End   string
+}
+
+// EscapeStyle describes how a closing delimiter is escaped inside a string
+type EscapeStyle int
+
+const (
+	// EscapeBackslash skips the character following a backslash
+	EscapeBackslash EscapeStyle = iota
+	// EscapeDoubled escapes the closing delimiter by repeating it, as in
+	// SQL's 'it''s'
+	EscapeDoubled
+	// EscapeNone ends the literal at the first closing delimiter
+	EscapeNone
+)
+
+// RawStyle describes raw strings whose closing delimiter is chosen in the
+// source
+type RawStyle int
+
+const (
+	// RawNone closes the literal with End
+	RawNone RawStyle = iota
+	// RawHashes closes the literal with a quote followed by as many '#' as
+	// were written around the opening quote, as in Rust's r#"..."# and
+	// Swift's #"..."#
+	RawHashes
+	// RawParens closes the literal with ')', the tag written between the
+	// quote and '(' and a quote, as in C++'s R"tag(...)tag"
+	RawParens
+)
+
+// StringLiteral describes one kind of string literal of a language
+type StringLiteral struct {
+	// Start opens the literal and End closes it; End defaults to Start
+	Start string
+	End   string
+
+	// Prefixes may precede Start without changing how the literal is
+	// scanned, like Python's f and b. They are matched case-insensitively.
+	Prefixes []string
+
+	Escape    EscapeStyle
+	Raw       RawStyle
+	MultiLine bool
+
+	// Char marks character literals, which hold a single character or
+	// escape sequence. A Start not followed by that shape and End is code,
+	// like Rust lifetimes ('a) and OCaml type variables.
+	Char bool
+}
+
+// MarkerRule restricts where a comment marker is recognised
+type MarkerRule struct {
+	// Column, when non-zero, is the column the marker must start at,
+	// counting from 1 at the start of the line
+	Column int
+
+	// Word requires the marker to stand alone rather than begin or end
+	// an identifier, like Batch's REM
+	Word bool
+
+	// IgnoreCase matches the marker in any case
+	IgnoreCase bool
+}
+
+// batchMarkerRules makes REM a case-insensitive keyword in Batch files
+var batchMarkerRules = map[string]MarkerRule{
+	"REM":  {Word: true, IgnoreCase: true},
+	"@REM": {Word: true, IgnoreCase: true},
+}
+
+// Heredoc describes a here-document opener, such as shell's << or PHP's <<<.
+// The word after it, optionally quoted, ends the body on a line of its own;
+// the body is string data, whatever comment markers or quotes it holds.
+type Heredoc struct {
+	Start string
+
+	// Flags lists characters that may follow Start to let the terminator
+	// line be indented, like bash's <<- and Ruby's <<~
+	Flags string
+
+	// Space allows whitespace before the word, as in shell's "cat << EOF"
+	Space bool
+
+	// Indented lets the terminator always be indented and be followed by
+	// code on its line, as PHP 7.3 does for "EOT;"
+	Indented bool
+}
+
+// EmbeddedRegion is an element of a container language, such as <script> in
+// Vue or HTML, whose content is written in another language. Language is the
+// extension of the language used when the element has no lang attribute; an
+// empty Language keeps such content in the container.
+type EmbeddedRegion struct {
+	Tag      string
+	Language string
 }
 
 // Languages defines all supported programming languages and their comment patterns
@@ -39,673 +192,848 @@ var Languages = func() map[string]*Language {
 	const capacity = 115
 	m := make(map[string]*Language, capacity)
 
+	// Documentation comment markers shared by several entries
+	javadoc := []string{"/**"}
+	doxygen := []string{"///", "//!", "/**", "/*!"}
+
+	// String literal kinds shared by several entries
+	cStrings := []StringLiteral{{Start: `"`}, {Start: "'", Char: true}}
+	cppStrings := []StringLiteral{
+		{Start: `R"`, Prefixes: []string{"u8", "u", "U", "L"}, Raw: RawParens, Escape: EscapeNone, MultiLine: true},
+		{Start: `"`},
+		{Start: "'", Char: true},
+	}
+	jsStrings := []StringLiteral{{Start: `"`}, {Start: "'"}, {Start: "`", MultiLine: true}}
+	mlStrings := []StringLiteral{{Start: `"`, MultiLine: true}, {Start: "'", Char: true}}
+	fsStrings := []StringLiteral{{Start: `"""`, Escape: EscapeNone, MultiLine: true}, {Start: `"`, MultiLine: true}, {Start: "'", Char: true}}
+	pyPrefixes := []string{"r", "u", "b", "f", "rb", "br", "fr", "rf"}
+	fortranStrings := []StringLiteral{{Start: `"`, Escape: EscapeDoubled}, {Start: "'", Escape: EscapeDoubled}}
+	shHeredocs := []Heredoc{{Start: "<<", Flags: "-", Space: true}}
+
+	// Branch keywords and operators counted towards complexity
+	cComplexity := []string{"if", "for", "while", "case", "catch", "&&", "||"}
+	goComplexity := []string{"if", "for", "case", "&&", "||"}
+	csComplexity := []string{"if", "for", "foreach", "while", "case", "catch", "&&", "||"}
+	pyComplexity := []string{"if", "elif", "for", "while", "except", "case", "and", "or"}
+	rbComplexity := []string{"if", "elsif", "unless", "while", "until", "for", "when", "rescue", "&&", "||", "and", "or"}
+	phpComplexity := []string{"if", "elseif", "for", "foreach", "while", "case", "catch", "&&", "||", "and", "or"}
+	plComplexity := []string{"if", "elsif", "unless", "for", "foreach", "while", "until", "&&", "||", "and", "or"}
+	shComplexity := []string{"if", "elif", "for", "while", "until", "&&", "||"}
+	rsComplexity := []string{"if", "for", "while", "loop", "=>", "&&", "||"}
+	swiftComplexity := []string{"if", "guard", "for", "while", "repeat", "case", "catch", "&&", "||"}
+	ktComplexity := []string{"if", "for", "while", "when", "catch", "&&", "||"}
+	scalaComplexity := []string{"if", "for", "while", "case", "catch", "&&", "||"}
+	luaComplexity := []string{"if", "elseif", "for", "while", "repeat", "and", "or"}
+	pyStrings := []StringLiteral{
+		{Start: `"""`, Prefixes: pyPrefixes, MultiLine: true},
+		{Start: "'''", Prefixes: pyPrefixes, MultiLine: true},
+		{Start: `"`, Prefixes: pyPrefixes},
+		{Start: "'", Prefixes: pyPrefixes},
+	}
+
+	// Perl POD: any =command at the start of a line opens a block that
+	// runs to =cut
+	var pod []BlockComment
+	podRules := map[string]MarkerRule{"=cut": {Column: 1, Word: true}}
+	for _, command := range []string{"=pod", "=head1", "=head2", "=head3", "=head4", "=over", "=item", "=back", "=begin", "=end", "=for", "=encoding"} {
+		pod = append(pod, BlockComment{command, "=cut"})
+		podRules[command] = MarkerRule{Column: 1, Word: true}
+	}
+
+	// Fixed-form Fortran marks comment lines in column 1 and fixed-format
+	// COBOL in column 7, the indicator area; *> and ! comment anywhere
+	fixedFortranRules := map[string]MarkerRule{"C": {Column: 1}, "c": {Column: 1}, "*": {Column: 1}}
+	cobolRules := map[string]MarkerRule{"*": {Column: 7}, "/": {Column: 7}}
+
 	m[".go"] = &Language{
 		Name:              "Go",
 		Extensions:        []string{".go"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "`"},
+		LineComments:      []string{"//"},
+		BlockComments:     []BlockComment{{"/*", "*/"}},
+		DocCommentsBefore: regexp.MustCompile(`^(package\s|(func(\s*\([^)]*\))?|type|var|const)\s+[A-Z])`),
+		Strings:           []StringLiteral{{Start: `"`}, {Start: "`", Escape: EscapeNone, MultiLine: true}, {Start: "'", Char: true}},
+		Branches:          goComplexity,
+		Declarations:      goDeclarations,
+		Statements:        goStatements,
 	}
 	m[".js"] = &Language{
-		Name:              "JavaScript",
-		Extensions:        []string{".js"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'", "`"},
+		Name:          "JavaScript",
+		Extensions:    []string{".js"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   javadoc,
+		Strings:       jsStrings,
+		Branches:      cComplexity,
+		Declarations:  jsDeclarations,
+		Statements:    jsStatements,
 	}
 	m[".ts"] = &Language{
-		Name:              "TypeScript",
-		Extensions:        []string{".ts"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'", "`"},
+		Name:          "TypeScript",
+		Extensions:    []string{".ts"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   javadoc,
+		Strings:       jsStrings,
+		Branches:      cComplexity,
+		Declarations:  tsDeclarations,
+		Statements:    jsStatements,
 	}
 	m[".tsx"] = &Language{
-		Name:              "TypeScript JSX",
-		Extensions:        []string{".tsx"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "TypeScript JSX",
+		Extensions:    []string{".tsx"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   javadoc,
+		Strings:       jsStrings,
+		Branches:      cComplexity,
+		Declarations:  tsDeclarations,
+		Statements:    jsStatements,
 	}
 	m[".jsx"] = &Language{
-		Name:              "JavaScript JSX",
-		Extensions:        []string{".jsx"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "JavaScript JSX",
+		Extensions:    []string{".jsx"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   javadoc,
+		Strings:       jsStrings,
+		Branches:      cComplexity,
+		Declarations:  jsDeclarations,
+		Statements:    jsStatements,
 	}
 	m[".html"] = &Language{
-		Name:              "HTML",
-		Extensions:        []string{".html", ".htm"},
-		SingleLineComment: "",
-		MultiLineStart:    "<!--",
-		MultiLineEnd:      "-->",
+		Name:            "HTML",
+		Extensions:      []string{".html", ".htm"},
+		BlockComments:   []BlockComment{{"<!--", "-->"}},
+		EmbeddedRegions: []EmbeddedRegion{{"script", ".js"}, {"style", ".css"}},
 	}
 	m[".htm"] = &Language{
-		Name:              "HTML",
-		Extensions:        []string{".html", ".htm"},
-		SingleLineComment: "",
-		MultiLineStart:    "<!--",
-		MultiLineEnd:      "-->",
+		Name:            "HTML",
+		Extensions:      []string{".html", ".htm"},
+		BlockComments:   []BlockComment{{"<!--", "-->"}},
+		EmbeddedRegions: []EmbeddedRegion{{"script", ".js"}, {"style", ".css"}},
 	}
 	m[".py"] = &Language{
-		Name:              "Python",
-		Extensions:        []string{".py"},
-		SingleLineComment: "#",
-		MultiLineStart:    `"""`,
-		MultiLineEnd:      `"""`,
-		StringDelimiters:  []string{"\"", "'"},
+		Name:                "Python",
+		Extensions:          []string{".py"},
+		LineComments:        []string{"#"},
+		DocStringDelimiters: []string{`"""`, "'''"},
+		DocStringScopes:     []string{"def", "async def", "class"},
+		Strings:             pyStrings,
+		Branches:            pyComplexity,
+		Declarations:        pyDeclarations,
+		Statements:          pyStatements,
 	}
 	m[".rb"] = &Language{
-		Name:              "Ruby",
-		Extensions:        []string{".rb"},
-		SingleLineComment: "#",
-		MultiLineStart:    "=begin",
-		MultiLineEnd:      "=end",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "Ruby",
+		Extensions:    []string{".rb"},
+		LineComments:  []string{"#"},
+		BlockComments: []BlockComment{{"=begin", "=end"}},
+		Strings:       []StringLiteral{{Start: `"`, MultiLine: true}, {Start: "'", MultiLine: true}},
+		Heredocs:      []Heredoc{{Start: "<<", Flags: "-~"}},
+		MarkerRules: map[string]MarkerRule{
+			"=begin": {Column: 1, Word: true},
+			"=end":   {Column: 1, Word: true},
+		},
+		Branches:     rbComplexity,
+		Declarations: rbDeclarations,
+		Statements:   rbStatements,
 	}
 	m[".java"] = &Language{
-		Name:              "Java",
-		Extensions:        []string{".java"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "Java",
+		Extensions:    []string{".java"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   javadoc,
+		Strings:       []StringLiteral{{Start: `"""`, MultiLine: true}, {Start: `"`}, {Start: "'", Char: true}},
+		Branches:      cComplexity,
+		Declarations:  javaDeclarations,
+		Statements:    cStatements,
 	}
 	m[".c"] = &Language{
-		Name:              "C",
-		Extensions:        []string{".c"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "C",
+		Extensions:    []string{".c"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   doxygen,
+		Strings:       cStrings,
+		Preprocessor:  true,
+		Branches:      cComplexity,
+		Declarations:  cDeclarations,
+		Statements:    cStatements,
+	}
+	m[".m"] = &Language{
+		Name:          "Objective-C",
+		Extensions:    []string{".m"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   doxygen,
+		Strings:       cStrings,
+		Preprocessor:  true,
+		Branches:      cComplexity,
+		Declarations:  objcDeclarations,
+		Statements:    cStatements,
 	}
 	m[".h"] = &Language{
-		Name:              "C Header",
-		Extensions:        []string{".h"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "C Header",
+		Extensions:    []string{".h"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   doxygen,
+		Strings:       cStrings,
+		Preprocessor:  true,
+		Branches:      cComplexity,
+		Declarations:  cDeclarations,
+		Statements:    cStatements,
 	}
 	m[".cpp"] = &Language{
-		Name:              "C++",
-		Extensions:        []string{".cpp", ".cc", ".cxx"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "C++",
+		Extensions:    []string{".cpp", ".cc", ".cxx"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   doxygen,
+		Strings:       cppStrings,
+		Preprocessor:  true,
+		Branches:      cComplexity,
+		Declarations:  cDeclarations,
+		Statements:    cStatements,
 	}
 	m[".cc"] = &Language{
-		Name:              "C++",
-		Extensions:        []string{".cpp", ".cc", ".cxx"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "C++",
+		Extensions:    []string{".cpp", ".cc", ".cxx"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   doxygen,
+		Strings:       cppStrings,
+		Preprocessor:  true,
+		Branches:      cComplexity,
+		Declarations:  cDeclarations,
+		Statements:    cStatements,
 	}
 	m[".hpp"] = &Language{
-		Name:              "C++ Header",
-		Extensions:        []string{".hpp"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "C++ Header",
+		Extensions:    []string{".hpp"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   doxygen,
+		Strings:       cppStrings,
+		Preprocessor:  true,
+		Branches:      cComplexity,
+		Declarations:  cDeclarations,
+		Statements:    cStatements,
 	}
 	m[".cs"] = &Language{
-		Name:              "C#",
-		Extensions:        []string{".cs"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "C#",
+		Extensions:    []string{".cs"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   []string{"///", "/**"},
+		Strings: []StringLiteral{
+			{Start: `"""`, Prefixes: []string{"$$", "$"}, Escape: EscapeNone, MultiLine: true},
+			{Start: `@"`, End: `"`, Prefixes: []string{"$"}, Escape: EscapeDoubled, MultiLine: true},
+			{Start: `@$"`, End: `"`, Escape: EscapeDoubled, MultiLine: true},
+			{Start: `"`},
+			{Start: "'", Char: true},
+		},
+		Branches:     csComplexity,
+		Declarations: csDeclarations,
+		Statements:   cStatements,
 	}
 	m[".php"] = &Language{
-		Name:              "PHP",
-		Extensions:        []string{".php"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "PHP",
+		Extensions:    []string{".php"},
+		LineComments:  []string{"//", "#"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   javadoc,
+		Strings:       []StringLiteral{{Start: `"`, MultiLine: true}, {Start: "'", MultiLine: true}},
+		Heredocs:      []Heredoc{{Start: "<<<", Indented: true}},
+		Branches:      phpComplexity,
+		Declarations:  phpDeclarations,
+		Statements:    cStatements,
 	}
 	m[".swift"] = &Language{
-		Name:              "Swift",
-		Extensions:        []string{".swift"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\""},
-		NestedComments:    true,
+		Name:           "Swift",
+		Extensions:     []string{".swift"},
+		LineComments:   []string{"//"},
+		BlockComments:  []BlockComment{{"/*", "*/"}},
+		DocComments:    []string{"///", "/**"},
+		NestedComments: true,
+		Strings:        []StringLiteral{{Start: "#", Raw: RawHashes, Escape: EscapeNone}, {Start: `"""`, MultiLine: true}, {Start: `"`}},
+		Branches:       swiftComplexity,
+		Declarations:   swiftDeclarations,
+		Statements:     jsStatements,
 	}
 	m[".kt"] = &Language{
-		Name:              "Kotlin",
-		Extensions:        []string{".kt"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\""},
-		NestedComments:    true,
+		Name:           "Kotlin",
+		Extensions:     []string{".kt"},
+		LineComments:   []string{"//"},
+		BlockComments:  []BlockComment{{"/*", "*/"}},
+		DocComments:    javadoc,
+		NestedComments: true,
+		Strings:        []StringLiteral{{Start: `"""`, Escape: EscapeNone, MultiLine: true}, {Start: `"`}, {Start: "'", Char: true}},
+		Branches:       ktComplexity,
+		Declarations:   ktDeclarations,
+		Statements:     jsStatements,
 	}
 	m[".rs"] = &Language{
-		Name:              "Rust",
-		Extensions:        []string{".rs"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\""},
-		NestedComments:    true,
+		Name:           "Rust",
+		Extensions:     []string{".rs"},
+		LineComments:   []string{"//"},
+		BlockComments:  []BlockComment{{"/*", "*/"}},
+		DocComments:    []string{"///", "//!", "/**", "/*!"},
+		NestedComments: true,
+		Strings: []StringLiteral{
+			{Start: "r", Prefixes: []string{"b", "c"}, Raw: RawHashes, Escape: EscapeNone, MultiLine: true},
+			{Start: `"`, Prefixes: []string{"b", "c"}, MultiLine: true},
+			{Start: "'", Prefixes: []string{"b"}, Char: true},
+		},
+		Branches:     rsComplexity,
+		Declarations: rsDeclarations,
+		Statements:   cStatements,
 	}
 	m[".scala"] = &Language{
-		Name:              "Scala",
-		Extensions:        []string{".scala"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "Scala",
+		Extensions:    []string{".scala"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   javadoc,
+		Strings:       []StringLiteral{{Start: `"""`, Escape: EscapeNone, MultiLine: true}, {Start: `"`}, {Start: "'", Char: true}},
+		Branches:      scalaComplexity,
+		Declarations:  scalaDeclarations,
+		Statements:    jsStatements,
 	}
 	m[".json"] = &Language{
-		Name:              "JSON",
-		Extensions:        []string{".json"},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "JSON",
+		Extensions: []string{".json"},
 	}
 	m[".yaml"] = &Language{
-		Name:              "YAML",
-		Extensions:        []string{".yaml", ".yml"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "YAML",
+		Extensions:   []string{".yaml", ".yml"},
+		LineComments: []string{"#"},
 	}
 	m[".yml"] = &Language{
-		Name:              "YAML",
-		Extensions:        []string{".yaml", ".yml"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "YAML",
+		Extensions:   []string{".yaml", ".yml"},
+		LineComments: []string{"#"},
 	}
 	m[".md"] = &Language{
-		Name:              "Markdown",
-		Extensions:        []string{".md"},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "Markdown",
+		Extensions: []string{".md"},
+		CodeFences: []string{"```", "~~~"},
 	}
 	m[".css"] = &Language{
-		Name:              "CSS",
-		Extensions:        []string{".css"},
-		SingleLineComment: "",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "CSS",
+		Extensions:    []string{".css"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".scss"] = &Language{
-		Name:              "SCSS",
-		Extensions:        []string{".scss"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "SCSS",
+		Extensions:    []string{".scss"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".sass"] = &Language{
-		Name:              "Sass",
-		Extensions:        []string{".sass"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Sass",
+		Extensions:    []string{".sass"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".less"] = &Language{
-		Name:              "Less",
-		Extensions:        []string{".less"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Less",
+		Extensions:    []string{".less"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".sql"] = &Language{
-		Name:              "SQL",
-		Extensions:        []string{".sql"},
-		SingleLineComment: "--",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "SQL",
+		Extensions:    []string{".sql"},
+		LineComments:  []string{"--", "#"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		Strings:       []StringLiteral{{Start: "'", Escape: EscapeDoubled, MultiLine: true}, {Start: `"`, Escape: EscapeDoubled, MultiLine: true}},
+		Statements:    sqlStatements,
 	}
 	m[".sh"] = &Language{
-		Name:              "Shell",
-		Extensions:        []string{".sh", ".bash"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Shell",
+		Extensions:   []string{".sh", ".bash"},
+		LineComments: []string{"#"},
+		Heredocs:     shHeredocs,
+		Branches:     shComplexity,
+		Declarations: shDeclarations,
+		Statements:   shStatements,
 	}
 	m[".bash"] = &Language{
-		Name:              "Shell",
-		Extensions:        []string{".sh", ".bash"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Shell",
+		Extensions:   []string{".sh", ".bash"},
+		LineComments: []string{"#"},
+		Heredocs:     shHeredocs,
+		Branches:     shComplexity,
+		Declarations: shDeclarations,
+		Statements:   shStatements,
 	}
 	m[".xml"] = &Language{
-		Name:              "XML",
-		Extensions:        []string{".xml"},
-		SingleLineComment: "",
-		MultiLineStart:    "<!--",
-		MultiLineEnd:      "-->",
+		Name:          "XML",
+		Extensions:    []string{".xml"},
+		BlockComments: []BlockComment{{"<!--", "-->"}},
 	}
 	m[".vue"] = &Language{
-		Name:              "Vue",
-		Extensions:        []string{".vue"},
-		SingleLineComment: "//",
-		MultiLineStart:    "<!--",
-		MultiLineEnd:      "-->",
+		Name:            "Vue",
+		Extensions:      []string{".vue"},
+		BlockComments:   []BlockComment{{"<!--", "-->"}},
+		EmbeddedRegions: []EmbeddedRegion{{"script", ".js"}, {"style", ".css"}, {"template", ""}},
 	}
 	m[".svelte"] = &Language{
-		Name:              "Svelte",
-		Extensions:        []string{".svelte"},
-		SingleLineComment: "//",
-		MultiLineStart:    "<!--",
-		MultiLineEnd:      "-->",
+		Name:            "Svelte",
+		Extensions:      []string{".svelte"},
+		BlockComments:   []BlockComment{{"<!--", "-->"}},
+		EmbeddedRegions: []EmbeddedRegion{{"script", ".js"}, {"style", ".css"}},
 	}
 	m[".lua"] = &Language{
-		Name:              "Lua",
-		Extensions:        []string{".lua"},
-		SingleLineComment: "--",
-		MultiLineStart:    "--[[",
-		MultiLineEnd:      "]]",
+		Name:          "Lua",
+		Extensions:    []string{".lua"},
+		LineComments:  []string{"--"},
+		BlockComments: []BlockComment{{"--[[", "]]"}},
+		Branches:      luaComplexity,
+		Declarations:  luaDeclarations,
+		Statements:    luaStatements,
 	}
 	m[".r"] = &Language{
-		Name:              "R",
-		Extensions:        []string{".r", ".R"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "R",
+		Extensions:   []string{".r", ".R"},
+		LineComments: []string{"#"},
 	}
 	m[".R"] = &Language{
-		Name:              "R",
-		Extensions:        []string{".r", ".R"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "R",
+		Extensions:   []string{".r", ".R"},
+		LineComments: []string{"#"},
 	}
 	m[".pl"] = &Language{
-		Name:              "Perl",
-		Extensions:        []string{".pl", ".pm"},
-		SingleLineComment: "#",
-		MultiLineStart:    "=pod",
-		MultiLineEnd:      "=cut",
+		Name:          "Perl",
+		Extensions:    []string{".pl", ".pm"},
+		LineComments:  []string{"#"},
+		BlockComments: pod,
+		Heredocs:      []Heredoc{{Start: "<<", Flags: "~"}},
+		MarkerRules:   podRules,
+		Branches:      plComplexity,
+		Declarations:  plDeclarations,
+		Statements:    plStatements,
 	}
 	m[".pm"] = &Language{
-		Name:              "Perl",
-		Extensions:        []string{".pl", ".pm"},
-		SingleLineComment: "#",
-		MultiLineStart:    "=pod",
-		MultiLineEnd:      "=cut",
+		Name:          "Perl",
+		Extensions:    []string{".pl", ".pm"},
+		LineComments:  []string{"#"},
+		BlockComments: pod,
+		Heredocs:      []Heredoc{{Start: "<<", Flags: "~"}},
+		MarkerRules:   podRules,
+		Branches:      plComplexity,
+		Declarations:  plDeclarations,
+		Statements:    plStatements,
 	}
 	m[".ex"] = &Language{
-		Name:              "Elixir",
-		Extensions:        []string{".ex", ".exs"},
-		SingleLineComment: "#",
-		MultiLineStart:    `"""`,
-		MultiLineEnd:      `"""`,
+		Name:          "Elixir",
+		Extensions:    []string{".ex", ".exs"},
+		LineComments:  []string{"#"},
+		BlockComments: []BlockComment{{`"""`, `"""`}},
 	}
 	m[".exs"] = &Language{
-		Name:              "Elixir",
-		Extensions:        []string{".ex", ".exs"},
-		SingleLineComment: "#",
-		MultiLineStart:    `"""`,
-		MultiLineEnd:      `"""`,
+		Name:          "Elixir",
+		Extensions:    []string{".ex", ".exs"},
+		LineComments:  []string{"#"},
+		BlockComments: []BlockComment{{`"""`, `"""`}},
 	}
 	m[".erl"] = &Language{
-		Name:              "Erlang",
-		Extensions:        []string{".erl"},
-		SingleLineComment: "%",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Erlang",
+		Extensions:   []string{".erl"},
+		LineComments: []string{"%"},
 	}
 	m[".hs"] = &Language{
-		Name:              "Haskell",
-		Extensions:        []string{".hs"},
-		SingleLineComment: "--",
-		MultiLineStart:    "{-",
-		MultiLineEnd:      "-}",
+		Name:          "Haskell",
+		Extensions:    []string{".hs"},
+		LineComments:  []string{"--"},
+		BlockComments: []BlockComment{{"{-", "-}"}},
 	}
 	m[".clj"] = &Language{
-		Name:              "Clojure",
-		Extensions:        []string{".clj"},
-		SingleLineComment: ";",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Clojure",
+		Extensions:   []string{".clj"},
+		LineComments: []string{";"},
 	}
 	m[".toml"] = &Language{
-		Name:              "TOML",
-		Extensions:        []string{".toml"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "TOML",
+		Extensions:   []string{".toml"},
+		LineComments: []string{"#"},
 	}
 	m[".ini"] = &Language{
-		Name:              "INI",
-		Extensions:        []string{".ini"},
-		SingleLineComment: ";",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "INI",
+		Extensions:   []string{".ini"},
+		LineComments: []string{";"},
 	}
 	m[".dockerfile"] = &Language{
-		Name:              "Dockerfile",
-		Extensions:        []string{".dockerfile"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Dockerfile",
+		Extensions:   []string{".dockerfile"},
+		LineComments: []string{"#"},
 	}
 	m[".makefile"] = &Language{
-		Name:              "Makefile",
-		Extensions:        []string{".makefile"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Makefile",
+		Extensions:   []string{".makefile"},
+		LineComments: []string{"#"},
 	}
 	m[".tf"] = &Language{
-		Name:              "Terraform",
-		Extensions:        []string{".tf"},
-		SingleLineComment: "#",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Terraform",
+		Extensions:    []string{".tf"},
+		LineComments:  []string{"#", "//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".proto"] = &Language{
-		Name:              "Protocol Buffers",
-		Extensions:        []string{".proto"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Protocol Buffers",
+		Extensions:    []string{".proto"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		Statements:    cStatements,
 	}
 	m[".graphql"] = &Language{
-		Name:              "GraphQL",
-		Extensions:        []string{".graphql", ".gql"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "GraphQL",
+		Extensions:   []string{".graphql", ".gql"},
+		LineComments: []string{"#"},
 	}
 	m[".gql"] = &Language{
-		Name:              "GraphQL",
-		Extensions:        []string{".graphql", ".gql"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "GraphQL",
+		Extensions:   []string{".graphql", ".gql"},
+		LineComments: []string{"#"},
 	}
 	m[".txt"] = &Language{
-		Name:              "Text",
-		Extensions:        []string{".txt"},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "Text",
+		Extensions: []string{".txt"},
 	}
 	m[".hcl"] = &Language{
-		Name:              "HCL",
-		Extensions:        []string{".hcl"},
-		SingleLineComment: "#",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "HCL",
+		Extensions:    []string{".hcl"},
+		LineComments:  []string{"#", "//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".y"] = &Language{
-		Name:              "Yacc",
-		Extensions:        []string{".y"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Yacc",
+		Extensions:    []string{".y"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		Statements:    cStatements,
 	}
 	m[".nix"] = &Language{
-		Name:              "Nix",
-		Extensions:        []string{".nix"},
-		SingleLineComment: "#",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Nix",
+		Extensions:    []string{".nix"},
+		LineComments:  []string{"#"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".json5"] = &Language{
-		Name:              "JSON5",
-		Extensions:        []string{".json5"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "JSON5",
+		Extensions:    []string{".json5"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".s"] = &Language{
-		Name:              "Assembly",
-		Extensions:        []string{".s", ".S", ".asm"},
-		SingleLineComment: ";",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Assembly",
+		Extensions:    []string{".s", ".S", ".asm"},
+		LineComments:  []string{";"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".S"] = &Language{
-		Name:              "Assembly",
-		Extensions:        []string{".s", ".S", ".asm"},
-		SingleLineComment: ";",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Assembly",
+		Extensions:    []string{".s", ".S", ".asm"},
+		LineComments:  []string{";"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".asm"] = &Language{
-		Name:              "Assembly",
-		Extensions:        []string{".s", ".S", ".asm"},
-		SingleLineComment: ";",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Assembly",
+		Extensions:    []string{".s", ".S", ".asm"},
+		LineComments:  []string{";"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".dart"] = &Language{
-		Name:              "Dart",
-		Extensions:        []string{".dart"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "Dart",
+		Extensions:    []string{".dart"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   []string{"///", "/**"},
+		Strings: []StringLiteral{
+			{Start: `r"""`, End: `"""`, Escape: EscapeNone, MultiLine: true},
+			{Start: "r'''", End: "'''", Escape: EscapeNone, MultiLine: true},
+			{Start: `"""`, MultiLine: true},
+			{Start: "'''", MultiLine: true},
+			{Start: `r"`, End: `"`, Escape: EscapeNone},
+			{Start: "r'", End: "'", Escape: EscapeNone},
+			{Start: `"`},
+			{Start: "'"},
+		},
+		Branches:   cComplexity,
+		Statements: cStatements,
 	}
 	m[".groovy"] = &Language{
-		Name:              "Groovy",
-		Extensions:        []string{".groovy"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "Groovy",
+		Extensions:    []string{".groovy"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   javadoc,
+		Strings:       []StringLiteral{{Start: `"""`, MultiLine: true}, {Start: "'''", MultiLine: true}, {Start: `"`}, {Start: "'"}},
+		Branches:      cComplexity,
+		Statements:    jsStatements,
 	}
 	m[".jl"] = &Language{
-		Name:              "Julia",
-		Extensions:        []string{".jl"},
-		SingleLineComment: "#",
-		MultiLineStart:    "#=",
-		MultiLineEnd:      "=#",
-		StringDelimiters:  []string{"\"", "'"},
+		Name:          "Julia",
+		Extensions:    []string{".jl"},
+		LineComments:  []string{"#"},
+		BlockComments: []BlockComment{{"#=", "=#"}},
+		Strings:       []StringLiteral{{Start: `"""`, MultiLine: true}, {Start: `"`}, {Start: "'", Char: true}},
 	}
 	m[".coffee"] = &Language{
-		Name:              "CoffeeScript",
-		Extensions:        []string{".coffee"},
-		SingleLineComment: "#",
-		MultiLineStart:    "###",
-		MultiLineEnd:      "###",
-		StringDelimiters:  []string{"\"", "'", "`"},
+		Name:          "CoffeeScript",
+		Extensions:    []string{".coffee"},
+		LineComments:  []string{"#"},
+		BlockComments: []BlockComment{{"###", "###"}},
+		Strings: []StringLiteral{
+			{Start: `"""`, MultiLine: true},
+			{Start: "'''", MultiLine: true},
+			{Start: `"`, MultiLine: true},
+			{Start: "'", MultiLine: true},
+			{Start: "`", MultiLine: true},
+		},
 	}
 	m[".pug"] = &Language{
-		Name:              "Pug",
-		Extensions:        []string{".pug"},
-		SingleLineComment: "//",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Pug",
+		Extensions:   []string{".pug"},
+		LineComments: []string{"//"},
 	}
 	m[".jade"] = &Language{
-		Name:              "Jade",
-		Extensions:        []string{".jade"},
-		SingleLineComment: "//",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Jade",
+		Extensions:   []string{".jade"},
+		LineComments: []string{"//"},
 	}
 	m[".twig"] = &Language{
-		Name:              "Twig",
-		Extensions:        []string{".twig"},
-		SingleLineComment: "{#",
-		MultiLineStart:    "{#",
-		MultiLineEnd:      "#}",
+		Name:          "Twig",
+		Extensions:    []string{".twig"},
+		BlockComments: []BlockComment{{"{#", "#}"}},
 	}
 	m[".ejs"] = &Language{
-		Name:              "EJS",
-		Extensions:        []string{".ejs"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "EJS",
+		Extensions:    []string{".ejs"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".haml"] = &Language{
-		Name:              "Haml",
-		Extensions:        []string{".haml"},
-		SingleLineComment: "-#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Haml",
+		Extensions:   []string{".haml"},
+		LineComments: []string{"-#"},
 	}
 	m[".tsv"] = &Language{
-		Name:              "TSV",
-		Extensions:        []string{".tsv"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "TSV",
+		Extensions:   []string{".tsv"},
+		LineComments: []string{"#"},
 	}
 	m[".csv"] = &Language{
-		Name:              "CSV",
-		Extensions:        []string{".csv"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "CSV",
+		Extensions:   []string{".csv"},
+		LineComments: []string{"#"},
 	}
 	m[".awk"] = &Language{
-		Name:              "AWK",
-		Extensions:        []string{".awk"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "AWK",
+		Extensions:   []string{".awk"},
+		LineComments: []string{"#"},
+		Statements:   jsStatements,
 	}
 	m[".sed"] = &Language{
-		Name:              "Sed",
-		Extensions:        []string{".sed"},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Sed",
+		Extensions:   []string{".sed"},
+		LineComments: []string{"#"},
 	}
 	m[".v"] = &Language{
-		Name:              "V",
-		Extensions:        []string{".v"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "V",
+		Extensions:    []string{".v"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".zig"] = &Language{
-		Name:              "Zig",
-		Extensions:        []string{".zig"},
-		SingleLineComment: "//",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Zig",
+		Extensions:   []string{".zig"},
+		LineComments: []string{"//"},
+		DocComments:  []string{"///", "//!"},
+		Statements:   cStatements,
 	}
 	m[".ada"] = &Language{
-		Name:              "Ada",
-		Extensions:        []string{".ada", ".adb", ".ads"},
-		SingleLineComment: "--",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Ada",
+		Extensions:   []string{".ada", ".adb", ".ads"},
+		LineComments: []string{"--"},
+		Statements:   sqlStatements,
 	}
 	m[".adb"] = &Language{
-		Name:              "Ada",
-		Extensions:        []string{".ada", ".adb", ".ads"},
-		SingleLineComment: "--",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Ada",
+		Extensions:   []string{".ada", ".adb", ".ads"},
+		LineComments: []string{"--"},
+		Statements:   sqlStatements,
 	}
 	m[".ml"] = &Language{
-		Name:              "OCaml",
-		Extensions:        []string{".ml"},
-		SingleLineComment: "(*",
-		MultiLineEnd:      "*)",
-		MultiLineStart:    "(*",
+		Name:          "OCaml",
+		Extensions:    []string{".ml"},
+		BlockComments: []BlockComment{{"(*", "*)"}},
+		Strings:       mlStrings,
 	}
 	m[".mli"] = &Language{
-		Name:              "OCaml Interface",
-		Extensions:        []string{".mli"},
-		SingleLineComment: "(*",
-		MultiLineEnd:      "*)",
-		MultiLineStart:    "(*",
+		Name:          "OCaml Interface",
+		Extensions:    []string{".mli"},
+		BlockComments: []BlockComment{{"(*", "*)"}},
+		Strings:       mlStrings,
 	}
 	m[".fs"] = &Language{
-		Name:              "F#",
-		Extensions:        []string{".fs", ".fsx", ".fsi"},
-		SingleLineComment: "//",
-		MultiLineStart:    "(*",
-		MultiLineEnd:      "*)",
+		Name:          "F#",
+		Extensions:    []string{".fs", ".fsx", ".fsi"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"(*", "*)"}},
+		DocComments:   []string{"///"},
+		Strings:       fsStrings,
 	}
 	m[".fsx"] = &Language{
-		Name:              "F# Script",
-		Extensions:        []string{".fs", ".fsx", ".fsi"},
-		SingleLineComment: "//",
-		MultiLineStart:    "(*",
-		MultiLineEnd:      "*)",
+		Name:          "F# Script",
+		Extensions:    []string{".fs", ".fsx", ".fsi"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"(*", "*)"}},
+		DocComments:   []string{"///"},
+		Strings:       fsStrings,
 	}
 	m[".nim"] = &Language{
-		Name:              "Nim",
-		Extensions:        []string{".nim"},
-		SingleLineComment: "#",
-		MultiLineStart:    "#[",
-		MultiLineEnd:      "]#",
+		Name:          "Nim",
+		Extensions:    []string{".nim"},
+		LineComments:  []string{"#"},
+		BlockComments: []BlockComment{{"#[", "]#"}},
 	}
 	m[".d"] = &Language{
-		Name:              "D",
-		Extensions:        []string{".d"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "D",
+		Extensions:    []string{".d"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		DocComments:   []string{"///", "/**"},
+		Statements:    cStatements,
 	}
 	m[".pas"] = &Language{
-		Name:              "Pascal",
-		Extensions:        []string{".pas"},
-		SingleLineComment: "//",
-		MultiLineStart:    "(*",
-		MultiLineEnd:      "*)",
+		Name:          "Pascal",
+		Extensions:    []string{".pas"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"(*", "*)"}},
+		Strings:       []StringLiteral{{Start: "'", Escape: EscapeDoubled}},
+		Statements:    sqlStatements,
 	}
 	m[".vb"] = &Language{
-		Name:              "Visual Basic",
-		Extensions:        []string{".vb"},
-		SingleLineComment: "'",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Visual Basic",
+		Extensions:   []string{".vb"},
+		LineComments: []string{"'"},
+		Strings:      []StringLiteral{{Start: `"`, Escape: EscapeDoubled}},
 	}
 	m[".vbs"] = &Language{
-		Name:              "VBScript",
-		Extensions:        []string{".vbs"},
-		SingleLineComment: "'",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "VBScript",
+		Extensions:   []string{".vbs"},
+		LineComments: []string{"'"},
+		Strings:      []StringLiteral{{Start: `"`, Escape: EscapeDoubled}},
 	}
 	m[".ps1"] = &Language{
-		Name:              "PowerShell",
-		Extensions:        []string{".ps1"},
-		SingleLineComment: "#",
-		MultiLineStart:    "<#",
-		MultiLineEnd:      "#>",
+		Name:          "PowerShell",
+		Extensions:    []string{".ps1"},
+		LineComments:  []string{"#"},
+		BlockComments: []BlockComment{{"<#", "#>"}},
+		Statements:    jsStatements,
+	}
+	m[".bat"] = &Language{
+		Name:         "Batch File",
+		Extensions:   []string{".bat", ".cmd"},
+		LineComments: []string{"REM", "@REM", "::"},
+		MarkerRules:  batchMarkerRules,
+	}
+	m[".cmd"] = &Language{
+		Name:         "Batch File",
+		Extensions:   []string{".bat", ".cmd"},
+		LineComments: []string{"REM", "@REM", "::"},
+		MarkerRules:  batchMarkerRules,
+	}
+	m[".f"] = &Language{
+		Name:         "Fortran",
+		Extensions:   []string{".f", ".for", ".f77"},
+		LineComments: []string{"!", "C", "c", "*"},
+		Strings:      fortranStrings,
+		MarkerRules:  fixedFortranRules,
+	}
+	m[".for"] = &Language{
+		Name:         "Fortran",
+		Extensions:   []string{".f", ".for", ".f77"},
+		LineComments: []string{"!", "C", "c", "*"},
+		Strings:      fortranStrings,
+		MarkerRules:  fixedFortranRules,
+	}
+	m[".f77"] = &Language{
+		Name:         "Fortran",
+		Extensions:   []string{".f", ".for", ".f77"},
+		LineComments: []string{"!", "C", "c", "*"},
+		Strings:      fortranStrings,
+		MarkerRules:  fixedFortranRules,
+	}
+	m[".f90"] = &Language{
+		Name:         "Fortran",
+		Extensions:   []string{".f90", ".f95", ".f03", ".f08"},
+		LineComments: []string{"!"},
+		Strings:      fortranStrings,
+	}
+	m[".f95"] = &Language{
+		Name:         "Fortran",
+		Extensions:   []string{".f90", ".f95", ".f03", ".f08"},
+		LineComments: []string{"!"},
+		Strings:      fortranStrings,
+	}
+	m[".f03"] = &Language{
+		Name:         "Fortran",
+		Extensions:   []string{".f90", ".f95", ".f03", ".f08"},
+		LineComments: []string{"!"},
+		Strings:      fortranStrings,
+	}
+	m[".f08"] = &Language{
+		Name:         "Fortran",
+		Extensions:   []string{".f90", ".f95", ".f03", ".f08"},
+		LineComments: []string{"!"},
+		Strings:      fortranStrings,
+	}
+	m[".cob"] = &Language{
+		Name:         "COBOL",
+		Extensions:   []string{".cob", ".cbl", ".cpy"},
+		LineComments: []string{"*>", "*", "/"},
+		Strings:      fortranStrings,
+		MarkerRules:  cobolRules,
+	}
+	m[".cbl"] = &Language{
+		Name:         "COBOL",
+		Extensions:   []string{".cob", ".cbl", ".cpy"},
+		LineComments: []string{"*>", "*", "/"},
+		Strings:      fortranStrings,
+		MarkerRules:  cobolRules,
+	}
+	m[".cpy"] = &Language{
+		Name:         "COBOL",
+		Extensions:   []string{".cob", ".cbl", ".cpy"},
+		LineComments: []string{"*>", "*", "/"},
+		Strings:      fortranStrings,
+		MarkerRules:  cobolRules,
 	}
 	m[".asmx"] = &Language{
-		Name:              "ASP.NET",
-		Extensions:        []string{".asmx"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "ASP.NET",
+		Extensions:    []string{".asmx"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".aspx"] = &Language{
-		Name:              "ASP.NET",
-		Extensions:        []string{".aspx"},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "ASP.NET",
+		Extensions:    []string{".aspx"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m[".cshtml"] = &Language{
-		Name:              "Razor",
-		Extensions:        []string{".cshtml"},
-		SingleLineComment: "@*",
-		MultiLineStart:    "@*",
-		MultiLineEnd:      "*@",
+		Name:          "Razor",
+		Extensions:    []string{".cshtml"},
+		BlockComments: []BlockComment{{"@*", "*@"}},
 	}
 	m[".vbhtml"] = &Language{
-		Name:              "Razor VB",
-		Extensions:        []string{".vbhtml"},
-		SingleLineComment: "@*",
-		MultiLineStart:    "@*",
-		MultiLineEnd:      "*@",
+		Name:          "Razor VB",
+		Extensions:    []string{".vbhtml"},
+		BlockComments: []BlockComment{{"@*", "*@"}},
 	}
 
 	return m
@@ -1041,251 +1369,187 @@ var BinaryExtensions = map[string]bool{
 var FilenameLanguages = func() map[string]*Language {
 	const capacity = 40
 	m := make(map[string]*Language, capacity)
+	shComplexity := []string{"if", "elif", "for", "while", "until", "&&", "||"}
 
 	m["Makefile"] = &Language{
-		Name:              "Makefile",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Makefile",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 	m["makefile"] = &Language{
-		Name:              "Makefile",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Makefile",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 	m["GNUmakefile"] = &Language{
-		Name:              "Makefile",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Makefile",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 	m["Dockerfile"] = &Language{
-		Name:              "Dockerfile",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Dockerfile",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 	m["dockerfile"] = &Language{
-		Name:              "Dockerfile",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Dockerfile",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 	m["LICENSE"] = &Language{
-		Name:              "License",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "License",
+		Extensions: []string{},
 	}
 	m["LICENSE.txt"] = &Language{
-		Name:              "License",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "License",
+		Extensions: []string{},
 	}
 	m["LICENSE.md"] = &Language{
-		Name:              "License",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "License",
+		Extensions: []string{},
 	}
 	m["LICENCE"] = &Language{
-		Name:              "License",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "License",
+		Extensions: []string{},
 	}
 	m["COPYING"] = &Language{
-		Name:              "License",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "License",
+		Extensions: []string{},
 	}
 	m["README"] = &Language{
-		Name:              "Readme",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "Readme",
+		Extensions: []string{},
 	}
 	m["README.txt"] = &Language{
-		Name:              "Readme",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "Readme",
+		Extensions: []string{},
 	}
 	m["Vagrantfile"] = &Language{
-		Name:              "Vagrantfile",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "=begin",
-		MultiLineEnd:      "=end",
+		Name:          "Vagrantfile",
+		Extensions:    []string{},
+		LineComments:  []string{"#"},
+		BlockComments: []BlockComment{{"=begin", "=end"}},
 	}
 	m["Gemfile"] = &Language{
-		Name:              "Gemfile",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "=begin",
-		MultiLineEnd:      "=end",
+		Name:          "Gemfile",
+		Extensions:    []string{},
+		LineComments:  []string{"#"},
+		BlockComments: []BlockComment{{"=begin", "=end"}},
 	}
 	m["Rakefile"] = &Language{
-		Name:              "Rakefile",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "=begin",
-		MultiLineEnd:      "=end",
+		Name:          "Rakefile",
+		Extensions:    []string{},
+		LineComments:  []string{"#"},
+		BlockComments: []BlockComment{{"=begin", "=end"}},
 	}
 	m["Procfile"] = &Language{
-		Name:              "Procfile",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Procfile",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 	m["CMakeLists.txt"] = &Language{
-		Name:              "CMake",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "CMake",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 	m["Jenkinsfile"] = &Language{
-		Name:              "Jenkinsfile",
-		Extensions:        []string{},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Jenkinsfile",
+		Extensions:    []string{},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		Statements:    jsStatements,
 	}
 	m["CHANGELOG"] = &Language{
-		Name:              "Changelog",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "Changelog",
+		Extensions: []string{},
 	}
 	m["CHANGELOG.md"] = &Language{
-		Name:              "Changelog",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "Changelog",
+		Extensions: []string{},
 	}
 	m["AUTHORS"] = &Language{
-		Name:              "Authors",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "Authors",
+		Extensions: []string{},
 	}
 	m["CONTRIBUTORS"] = &Language{
-		Name:              "Contributors",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "Contributors",
+		Extensions: []string{},
 	}
 	m["build.gradle"] = &Language{
-		Name:              "Gradle",
-		Extensions:        []string{},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Gradle",
+		Extensions:    []string{},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		Statements:    jsStatements,
 	}
 	m["build.gradle.kts"] = &Language{
-		Name:              "Gradle Kotlin",
-		Extensions:        []string{},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Gradle Kotlin",
+		Extensions:    []string{},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m["gradlew"] = &Language{
-		Name:              "Shell Script",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Shell Script",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
+		Branches:     shComplexity,
+		Declarations: shDeclarations,
+		Statements:   shStatements,
 	}
 	m["gradlew.bat"] = &Language{
-		Name:              "Batch File",
-		Extensions:        []string{},
-		SingleLineComment: "REM",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Batch File",
+		Extensions:   []string{},
+		LineComments: []string{"REM", "@REM", "::"},
+		MarkerRules:  batchMarkerRules,
 	}
 	m["mvnw"] = &Language{
-		Name:              "Shell Script",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Shell Script",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
+		Branches:     shComplexity,
+		Declarations: shDeclarations,
+		Statements:   shStatements,
 	}
 	m["mvnw.cmd"] = &Language{
-		Name:              "Batch File",
-		Extensions:        []string{},
-		SingleLineComment: "REM",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Batch File",
+		Extensions:   []string{},
+		LineComments: []string{"REM", "@REM", "::"},
+		MarkerRules:  batchMarkerRules,
 	}
 	m["pom.xml"] = &Language{
-		Name:              "Maven POM",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "<!--",
-		MultiLineEnd:      "-->",
+		Name:          "Maven POM",
+		Extensions:    []string{},
+		BlockComments: []BlockComment{{"<!--", "-->"}},
 	}
 	m["composer.json"] = &Language{
-		Name:              "Composer",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "Composer",
+		Extensions: []string{},
 	}
 	m["package.json"] = &Language{
-		Name:              "NPM Package",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "NPM Package",
+		Extensions: []string{},
 	}
 	m["tsconfig.json"] = &Language{
-		Name:              "TypeScript Config",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "TypeScript Config",
+		Extensions: []string{},
 	}
 	m["webpack.config.js"] = &Language{
-		Name:              "Webpack Config",
-		Extensions:        []string{},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Webpack Config",
+		Extensions:    []string{},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m["gruntfile.js"] = &Language{
-		Name:              "Grunt",
-		Extensions:        []string{},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Grunt",
+		Extensions:    []string{},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 	m["gulpfile.js"] = &Language{
-		Name:              "Gulp",
-		Extensions:        []string{},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "Gulp",
+		Extensions:    []string{},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 
 	return m
@@ -1297,120 +1561,222 @@ var HiddenFileLanguages = func() map[string]*Language {
 
 	m := make(map[string]*Language, estimatedCapacity)
 	m[".gitignore"] = &Language{
-		Name:              "Git Config",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Git Config",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 
 	m[".dockerignore"] = &Language{
-		Name:              "Docker Config",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Docker Config",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 
 	m[".gitattributes"] = &Language{
-		Name:              "Git Config",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Git Config",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 
 	m[".editorconfig"] = &Language{
-		Name:              "EditorConfig",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "EditorConfig",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 
 	m[".eslintrc"] = &Language{
-		Name:              "ESLint Config",
-		Extensions:        []string{},
-		SingleLineComment: "//",
-		MultiLineStart:    "/*",
-		MultiLineEnd:      "*/",
+		Name:          "ESLint Config",
+		Extensions:    []string{},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
 	}
 
 	m[".prettierrc"] = &Language{
-		Name:              "Prettier Config",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "Prettier Config",
+		Extensions: []string{},
 	}
 
 	m[".babelrc"] = &Language{
-		Name:              "Babel Config",
-		Extensions:        []string{},
-		SingleLineComment: "",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:       "Babel Config",
+		Extensions: []string{},
 	}
 
 	m[".npmrc"] = &Language{
-		Name:              "NPM Config",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "NPM Config",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 
 	m[".yarnrc"] = &Language{
-		Name:              "Yarn Config",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Yarn Config",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 
 	m[".env"] = &Language{
-		Name:              "Environment",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Environment",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 
 	m[".env.example"] = &Language{
-		Name:              "Environment",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Environment",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 
 	m[".env.local"] = &Language{
-		Name:              "Environment",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Environment",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 
 	m[".htaccess"] = &Language{
-		Name:              "Apache Config",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Apache Config",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
 	}
 
 	m[".travis.yml"] = &Language{
-		Name:              "Travis CI",
-		Extensions:        []string{},
-		SingleLineComment: "#",
-		MultiLineStart:    "",
-		MultiLineEnd:      "",
+		Name:         "Travis CI",
+		Extensions:   []string{},
+		LineComments: []string{"#"},
+	}
+
+	return m
+}()
+
+// HeuristicLanguages defines, by name, languages that share every extension
+// they use with an entry in Languages and are only chosen from file content
+var HeuristicLanguages = func() map[string]*Language {
+	m := make(map[string]*Language)
+
+	m["MATLAB"] = &Language{
+		Name:          "MATLAB",
+		Extensions:    []string{".m"},
+		LineComments:  []string{"%"},
+		BlockComments: []BlockComment{{"%{", "%}"}},
+		Strings:       []StringLiteral{{Start: `"`}},
+	}
+	m["Prolog"] = &Language{
+		Name:          "Prolog",
+		Extensions:    []string{".pl"},
+		LineComments:  []string{"%"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		Strings:       []StringLiteral{{Start: `"`}},
+	}
+	m["Verilog"] = &Language{
+		Name:          "Verilog",
+		Extensions:    []string{".v"},
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{"/*", "*/"}},
+		Strings:       []StringLiteral{{Start: `"`}},
+		Statements:    sqlStatements,
+	}
+	m["Qt Translation"] = &Language{
+		Name:          "Qt Translation",
+		Extensions:    []string{".ts"},
+		BlockComments: []BlockComment{{"<!--", "-->"}},
 	}
 
 	return m
 }()
 
+// LanguageAliases maps the names used to tag embedded code, like lang="ts"
+// attributes and Markdown fence info strings, to language extensions
+var LanguageAliases = map[string]string{
+	"javascript": ".js",
+	"mjs":        ".js",
+	"cjs":        ".js",
+	"node":       ".js",
+	"typescript": ".ts",
+	"python":     ".py",
+	"python3":    ".py",
+	"golang":     ".go",
+	"ruby":       ".rb",
+	"rust":       ".rs",
+	"kotlin":     ".kt",
+	"csharp":     ".cs",
+	"c#":         ".cs",
+	"cpp":        ".cpp",
+	"c++":        ".cpp",
+	"shell":      ".sh",
+	"sh":         ".sh",
+	"zsh":        ".sh",
+	"console":    ".sh",
+	"postcss":    ".css",
+	"markdown":   ".md",
+	"yml":        ".yaml",
+	"terraform":  ".tf",
+	"protobuf":   ".proto",
+	"haskell":    ".hs",
+	"elixir":     ".ex",
+	"erlang":     ".erl",
+	"clojure":    ".clj",
+	"perl":       ".pl",
+	"powershell": ".ps1",
+	"ocaml":      ".ml",
+	"fsharp":     ".fs",
+	"pascal":     ".pas",
+	"vb":         ".vb",
+}
+
+// InterpreterLanguages maps the interpreters named in a shebang line, with
+// any version suffix removed, to language extensions
+var InterpreterLanguages = map[string]string{
+	"sh":         ".sh",
+	"bash":       ".sh",
+	"dash":       ".sh",
+	"ash":        ".sh",
+	"ksh":        ".sh",
+	"mksh":       ".sh",
+	"zsh":        ".sh",
+	"python":     ".py",
+	"pypy":       ".py",
+	"node":       ".js",
+	"nodejs":     ".js",
+	"deno":       ".ts",
+	"bun":        ".js",
+	"ts-node":    ".ts",
+	"ruby":       ".rb",
+	"perl":       ".pl",
+	"php":        ".php",
+	"lua":        ".lua",
+	"luajit":     ".lua",
+	"rscript":    ".r",
+	"awk":        ".awk",
+	"gawk":       ".awk",
+	"mawk":       ".awk",
+	"sed":        ".sed",
+	"make":       ".makefile",
+	"elixir":     ".ex",
+	"escript":    ".erl",
+	"runghc":     ".hs",
+	"runhaskell": ".hs",
+	"stack":      ".hs",
+	"groovy":     ".groovy",
+	"scala":      ".scala",
+	"julia":      ".jl",
+	"swift":      ".swift",
+	"pwsh":       ".ps1",
+	"powershell": ".ps1",
+	"ocaml":      ".ml",
+	"nim":        ".nim",
+}
+
+// GetLanguageByAlias returns the language definition for a language name
+// such as "ts" or "python", falling back to treating it as an extension
+func GetLanguageByAlias(alias string) *Language {
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	if alias == "" {
+		return nil
+	}
+	if ext, ok := LanguageAliases[alias]; ok {
+		return GetLanguage(ext)
+	}
+	return GetLanguage("." + alias)
+}
+
 // GetLanguage returns the language definition for a given file extension
 func GetLanguage(ext string) *Language {
 	if lang, ok := languageSync.Load(ext); ok {
@@ -1432,6 +1798,14 @@ func GetLanguageByFilename(filename string) *Language {
 	return nil
 }
 
+// GetLanguageByName returns the language definition with the given name
+func GetLanguageByName(name string) *Language {
+	if lang, ok := nameLanguageSync.Load(name); ok {
+		return lang.(*Language)
+	}
+	return nil
+}
+
 // IsBinaryExtension checks if the file extension is a binary file
 func IsBinaryExtension(ext string) bool {
 	return BinaryExtensions[ext]
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -90,21 +91,23 @@ func TestIsBinaryExtension(t *testing.T) {
 
 func TestLanguageCommentPatterns(t *testing.T) {
 	tests := []struct {
-		ext               string
-		singleLineComment string
-		multiLineStart    string
-		multiLineEnd      string
+		ext           string
+		lineComments  []string
+		blockComments []BlockComment
 	}{
-		{".go", "//", "/*", "*/"},
-		{".js", "//", "/*", "*/"},
-		{".py", "#", `"""`, `"""`},
-		{".html", "", "<!--", "-->"},
-		{".css", "", "/*", "*/"},
-		{".yaml", "#", "", ""},
-		{".sh", "#", "", ""},
-		{".sql", "--", "/*", "*/"},
-		{".lua", "--", "--[[", "]]"},
-		{".hs", "--", "{-", "-}"},
+		{".go", []string{"//"}, []BlockComment{{"/*", "*/"}}},
+		{".js", []string{"//"}, []BlockComment{{"/*", "*/"}}},
+		{".py", []string{"#"}, nil},
+		{".html", nil, []BlockComment{{"<!--", "-->"}}},
+		{".css", nil, []BlockComment{{"/*", "*/"}}},
+		{".yaml", []string{"#"}, nil},
+		{".sh", []string{"#"}, nil},
+		{".sql", []string{"--", "#"}, []BlockComment{{"/*", "*/"}}},
+		{".php", []string{"//", "#"}, []BlockComment{{"/*", "*/"}}},
+		{".tf", []string{"#", "//"}, []BlockComment{{"/*", "*/"}}},
+		{".lua", []string{"--"}, []BlockComment{{"--[[", "]]"}}},
+		{".hs", []string{"--"}, []BlockComment{{"{-", "-}"}}},
+		{".ml", nil, []BlockComment{{"(*", "*)"}}},
 	}
 
 	for _, tt := range tests {
@@ -114,14 +117,11 @@ func TestLanguageCommentPatterns(t *testing.T) {
 				t.Fatalf("GetLanguage(%q) returned nil", tt.ext)
 			}
 
-			if lang.SingleLineComment != tt.singleLineComment {
-				t.Errorf("SingleLineComment = %q, want %q", lang.SingleLineComment, tt.singleLineComment)
+			if !reflect.DeepEqual(lang.LineComments, tt.lineComments) {
+				t.Errorf("LineComments = %q, want %q", lang.LineComments, tt.lineComments)
 			}
-			if lang.MultiLineStart != tt.multiLineStart {
-				t.Errorf("MultiLineStart = %q, want %q", lang.MultiLineStart, tt.multiLineStart)
-			}
-			if lang.MultiLineEnd != tt.multiLineEnd {
-				t.Errorf("MultiLineEnd = %q, want %q", lang.MultiLineEnd, tt.multiLineEnd)
+			if !reflect.DeepEqual(lang.BlockComments, tt.blockComments) {
+				t.Errorf("BlockComments = %q, want %q", lang.BlockComments, tt.blockComments)
 			}
 		})
 	}
@@ -168,3 +168,30 @@ func TestGetLanguageByFilename(t *testing.T) {
 		})
 	}
 }
+
+func TestGetLanguageByAlias(t *testing.T) {
+	tests := []struct {
+		alias    string
+		wantName string
+	}{
+		{"ts", "TypeScript"},
+		{"TypeScript", "TypeScript"},
+		{"scss", "SCSS"},
+		{"python", "Python"},
+		{"bash", "Shell"},
+		{"go", "Go"},
+		{"", ""},
+		{"nonexistent", ""},
+	}
+
+	for _, tt := range tests {
+		lang := GetLanguageByAlias(tt.alias)
+		name := ""
+		if lang != nil {
+			name = lang.Name
+		}
+		if name != tt.wantName {
+			t.Errorf("GetLanguageByAlias(%q) = %q, want %q", tt.alias, name, tt.wantName)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRawTag is the longest tag accepted between R" and ( in a C++ raw string
+const maxRawTag = 16
+
+// matchStringLiteral reports whether a string literal of one of the given
+// kinds opens at line[i:]. It returns the kind, the length of the opening
+// delimiter including any prefix, and the delimiter that closes the literal.
+// Character literals are matched whole, with an empty closing delimiter.
+func matchStringLiteral(line string, i int, literals []StringLiteral) (StringLiteral, int, string, bool) {
+	rest := line[i:]
+	for _, lit := range literals {
+		n := matchLiteralStart(rest, lit)
+		if n == 0 {
+			continue
+		}
+
+		// A start that begins like an identifier, such as Rust's r or
+		// Python's f, must not continue one
+		if isIdentChar(rest[0]) && i > 0 && isIdentChar(line[i-1]) {
+			continue
+		}
+
+		end := lit.End
+		if end == "" {
+			end = lit.Start
+		}
+
+		if lit.Char {
+			size := matchCharBody(rest[n:], end)
+			if size == 0 {
+				continue
+			}
+			return lit, n + size, "", true
+		}
+
+		switch lit.Raw {
+		case RawHashes:
+			hashes := strings.Count(lit.Start, "#")
+			for n < len(rest) && rest[n] == '#' {
+				hashes++
+				n++
+			}
+			if n >= len(rest) || rest[n] != '"' {
+				continue
+			}
+			n++
+			end = `"` + strings.Repeat("#", hashes)
+		case RawParens:
+			open := strings.IndexByte(rest[n:], '(')
+			if open < 0 || open > maxRawTag || strings.ContainsAny(rest[n:n+open], " \t\\)\"") {
+				continue
+			}
+			end = ")" + rest[n:n+open] + `"`
+			n += open + 1
+		}
+
+		return lit, n, end, true
+	}
+	return StringLiteral{}, 0, "", false
+}
+
+// maxCharEscape is the longest escape sequence accepted in a character
+// literal, enough for Rust's '\u{10FFFF}'
+const maxCharEscape = 10
+
+// matchCharBody returns the length of a character literal's body and closing
+// delimiter at the start of s, or 0 if s does not hold one
+func matchCharBody(s, end string) int {
+	if s == "" {
+		return 0
+	}
+	if s[0] == '\\' {
+		for n := 2; n <= maxCharEscape+1 && n < len(s); n++ {
+			if strings.HasPrefix(s[n:], end) {
+				return n + len(end)
+			}
+		}
+		return 0
+	}
+	_, size := utf8.DecodeRuneInString(s)
+	if strings.HasPrefix(s[size:], end) {
+		return size + len(end)
+	}
+	return 0
+}
+
+// matchLiteralStart returns the length of the literal's start, including an
+// optional prefix, at the beginning of s, or 0 if it does not open there
+func matchLiteralStart(s string, lit StringLiteral) int {
+	if lit.Start == "" {
+		return 0
+	}
+	if strings.HasPrefix(s, lit.Start) {
+		return len(lit.Start)
+	}
+	for _, prefix := range lit.Prefixes {
+		if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) && strings.HasPrefix(s[len(prefix):], lit.Start) {
+			return len(prefix) + len(lit.Start)
+		}
+	}
+	return 0
+}
+
+// heredocEnd is the terminator of a here-document body
+type heredocEnd struct {
+	word string
+	line int
+
+	// indented lets the terminator be indented; trailing lets code
+	// follow it on its line
+	indented bool
+	trailing bool
+}
+
+// matchHeredoc reports whether a here-document opens at line[i:]. It returns
+// the terminator of its body and the length of the opener including the word.
+func matchHeredoc(line string, i int, heredocs []Heredoc) (heredocEnd, int, bool) {
+	rest := line[i:]
+	for _, h := range heredocs {
+		if !strings.HasPrefix(rest, h.Start) {
+			continue
+		}
+
+		// A longer run of the opener's last character is another operator,
+		// like bash's <<< here-string
+		last := h.Start[len(h.Start)-1]
+		n := len(h.Start)
+		if (i > 0 && line[i-1] == last) || (n < len(rest) && rest[n] == last) {
+			continue
+		}
+
+		end := heredocEnd{indented: h.Indented, trailing: h.Indented}
+		if n < len(rest) && strings.IndexByte(h.Flags, rest[n]) >= 0 {
+			end.indented = true
+			n++
+		}
+		if h.Space {
+			for n < len(rest) && isWhitespace(rest[n]) {
+				n++
+			}
+		}
+		if n >= len(rest) {
+			continue
+		}
+
+		if quote := rest[n]; quote == '\'' || quote == '"' || quote == '`' {
+			closing := strings.IndexByte(rest[n+1:], quote)
+			if closing <= 0 {
+				continue
+			}
+			end.word = rest[n+1 : n+1+closing]
+			return end, n + closing + 2, true
+		}
+
+		// Shell allows \EOF as a quoted word
+		if rest[n] == '\\' {
+			n++
+		}
+		start := n
+		for n < len(rest) && isIdentChar(rest[n]) {
+			n++
+		}
+		if n == start || rest[start] >= '0' && rest[start] <= '9' {
+			continue
+		}
+		end.word = rest[start:n]
+		return end, n, true
+	}
+	return heredocEnd{}, 0, false
+}
+
+// matchHeredocEnd reports whether a line terminates a here-document body,
+// and the length of the terminator, after which code may follow
+func matchHeredocEnd(line string, end heredocEnd) (int, bool) {
+	n := 0
+	if end.indented {
+		n = len(line) - len(strings.TrimLeft(line, " \t"))
+	}
+	rest, ok := strings.CutPrefix(line[n:], end.word)
+	if !ok {
+		return 0, false
+	}
+	n += len(end.word)
+	if end.trailing {
+		return n, rest == "" || !isIdentChar(rest[0])
+	}
+	return n, rest == ""
+}
+
+// isIdentChar reports whether c may appear in an identifier
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// containsString reports whether s is one of the candidates
+func containsString(candidates []string, s string) bool {
+	for _, c := range candidates {
+		if c == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMatchStringLiteral(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		pos     int
+		ext     string
+		wantOK  bool
+		wantLen int
+		wantEnd string
+	}{
+		{"Rust raw string", `let s = r#"a"b"#;`, 8, ".rs", true, 3, `"#`},
+		{"Rust raw byte string", `br##"x"##`, 0, ".rs", true, 5, `"##`},
+		{"Rust identifier ending in r", `for"`, 2, ".rs", false, 0, ""},
+		{"C++ raw string", `auto s = R"sql(SELECT 1)sql";`, 9, ".cpp", true, 6, `)sql"`},
+		{"C++ prefixed raw string", `u8R"(x)"`, 0, ".cpp", true, 5, `)"`},
+		{"C++ plain string", `"abc"`, 0, ".cpp", true, 1, `"`},
+		{"C# verbatim string", `@"C:\dir\"`, 0, ".cs", true, 2, `"`},
+		{"C# interpolated verbatim string", `$@"{x}"`, 0, ".cs", true, 3, `"`},
+		{"Python prefixed docstring", `rb"""x"""`, 0, ".py", true, 5, `"""`},
+		{"Swift raw string", `#"a"#`, 0, ".swift", true, 2, `"#`},
+		{"Swift directive", `#if DEBUG`, 0, ".swift", false, 0, ""},
+		{"Rust char literal", `'x'`, 0, ".rs", true, 3, ""},
+		{"Rust byte char literal", `b'\n'`, 0, ".rs", true, 5, ""},
+		{"Rust lifetime", `&'a str`, 1, ".rs", false, 0, ""},
+		{"Rust static lifetime", `'static`, 0, ".rs", false, 0, ""},
+		{"C escaped quote char", `'\''`, 0, ".c", true, 4, ""},
+		{"Go multibyte rune", `'é'`, 0, ".go", true, 4, ""},
+		{"No literal", `x = 1`, 0, ".go", false, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, n, end, ok := matchStringLiteral(tt.line, tt.pos, Languages[tt.ext].Strings)
+			if ok != tt.wantOK || n != tt.wantLen || end != tt.wantEnd {
+				t.Errorf("matchStringLiteral(%q, %d) = (%d, %q, %v), want (%d, %q, %v)",
+					tt.line, tt.pos, n, end, ok, tt.wantLen, tt.wantEnd, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMatchHeredoc(t *testing.T) {
+	tests := []struct {
+		name         string
+		line         string
+		pos          int
+		ext          string
+		wantOK       bool
+		wantLen      int
+		wantWord     string
+		wantIndented bool
+	}{
+		{"Shell plain", `cat <<EOF`, 4, ".sh", true, 5, "EOF", false},
+		{"Shell tab-stripped", `cat <<-EOF`, 4, ".sh", true, 6, "EOF", true},
+		{"Shell quoted with space", `cat << 'END' > out`, 4, ".sh", true, 8, "END", false},
+		{"Shell backslash", `cat <<\EOF`, 4, ".sh", true, 6, "EOF", false},
+		{"Shell here-string", `cat <<< "$x"`, 4, ".sh", false, 0, "", false},
+		{"Shell here-string second char", `cat <<< "$x"`, 5, ".sh", false, 0, "", false},
+		{"Ruby squiggly", `sql = <<~SQL.strip`, 6, ".rb", true, 6, "SQL", true},
+		{"Ruby append", `list << item`, 5, ".rb", false, 0, "", false},
+		{"Ruby singleton class", `class << self`, 6, ".rb", false, 0, "", false},
+		{"Perl quoted", `print <<"EOT";`, 6, ".pl", true, 7, "EOT", false},
+		{"PHP heredoc", `$s = <<<EOT`, 5, ".php", true, 6, "EOT", true},
+		{"PHP nowdoc", `$s = <<<'EOT'`, 5, ".php", true, 8, "EOT", true},
+		{"Digit after opener", `echo $((1<<2))`, 8, ".sh", false, 0, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, n, ok := matchHeredoc(tt.line, tt.pos, Languages[tt.ext].Heredocs)
+			if ok != tt.wantOK || n != tt.wantLen || end.word != tt.wantWord || end.indented != tt.wantIndented {
+				t.Errorf("matchHeredoc(%q, %d) = (%q, indented %v, %d, %v), want (%q, indented %v, %d, %v)",
+					tt.line, tt.pos, end.word, end.indented, n, ok, tt.wantWord, tt.wantIndented, tt.wantLen, tt.wantOK)
+			}
+		})
+	}
+}
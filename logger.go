@@ -38,7 +38,8 @@ type Logger struct {
 var defaultLogger *Logger
 
 func init() {
-	defaultLogger = NewLogger(LogLevelInfo, os.Stdout, os.Stderr)
+	// Log messages go to stderr, keeping them out of results on stdout
+	defaultLogger = NewLogger(LogLevelInfo, os.Stderr, os.Stderr)
 }
 
 // NewLogger creates a new Logger instance
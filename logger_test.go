@@ -104,7 +104,7 @@ func TestGlobalLogFunctions(t *testing.T) {
 
 	// Reset to original outputs after test
 	defer func() {
-		SetLogOutput(os.Stdout)
+		SetLogOutput(os.Stderr)
 		SetLogErrorOutput(os.Stderr)
 	}()
 
@@ -157,7 +157,7 @@ func TestLogFileDirectoryErrors(t *testing.T) {
 	SetLogOutput(&out)
 	SetLogErrorOutput(&out)
 	defer func() {
-		SetLogOutput(os.Stdout)
+		SetLogOutput(os.Stderr)
 		SetLogErrorOutput(os.Stderr)
 	}()
 
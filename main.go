@@ -27,6 +27,20 @@ type Config struct {
 	ShowErrors      bool
 	Verbose         bool
 	Quiet           bool
+	MarkdownCode    bool
+	DisabledCode    bool
+	ComplexFiles    int
+	Breakdown       bool
+	Duplicates      bool
+	Dedupe          bool
+	Cocomo          bool
+	Cost            CostOptions
+	Annotations     []string
+	AnnotationList  bool
+	Fallbacks       map[string]string
+
+	IncludeGenerated bool
+	IncludeMinified  bool
 }
 
 func main() {
@@ -55,38 +69,60 @@ func Run(config *Config) error {
 		return err
 	}
 
+	// Listing annotations counts the default markers unless others are given
+	if config.AnnotationList && len(config.Annotations) == 0 {
+		config.Annotations = DefaultAnnotations
+	}
+
+	countOptions := CountOptions{
+		MarkdownCode:   config.MarkdownCode,
+		DisabledCode:   config.DisabledCode,
+		Annotations:    config.Annotations,
+		AnnotationList: config.AnnotationList,
+	}
+
+	if err := ValidateFallbacks(config.Fallbacks); err != nil {
+		return err
+	}
+	detectOptions := DetectOptions{
+		Fallbacks: config.Fallbacks,
+	}
+
+	if config.Cocomo {
+		if err := ValidateCostOptions(config.Cost); err != nil {
+			return err
+		}
+	}
+
 	// Start timing
 	startTime := time.Now()
 
 	var fileStats []*FileStats
 	var errors []error
+	var skipped []SkippedFile
 	processedFiles := 0
-	skippedFiles := 0
 
 	if !info.IsDir() {
 		// Single file mode
 		ext := strings.ToLower(filepath.Ext(config.Path))
-		lang := GetLanguage(ext)
-		if lang == nil {
-			lang = GetLanguageByFilename(filepath.Base(config.Path))
-		}
-
-		if lang == nil {
-			skippedFiles = 1
-		} else {
-			stats, err := CountLines(config.Path, lang)
-			if err != nil {
-				errors = append(errors, err)
-			} else {
-				stats.Extension = ext
-				fileStats = append(fileStats, stats)
-				processedFiles = 1
-			}
+		stats, reason, err := countFile(config.Path, nil, detectOptions, countOptions)
+
+		switch {
+		case err != nil:
+			errors = append(errors, err)
+		case reason != "":
+			skipped = append(skipped, SkippedFile{Path: config.Path, Reason: reason})
+		default:
+			stats.Extension = ext
+			fileStats = append(fileStats, stats)
+			processedFiles = 1
 		}
 	} else {
 		// Directory mode
 		walker := NewWalker(config.Path, config.Workers)
 		walker.SetIncludeHidden(config.IncludeHidden)
+		walker.SetCountOptions(countOptions)
+		walker.SetDetectOptions(detectOptions)
 
 		// Add any additional exclude directories
 		for _, dir := range config.ExcludeDirs {
@@ -106,37 +142,72 @@ func Run(config *Config) error {
 		// Walk and count
 		fileStats, errors = walker.Walk()
 		processedFiles = walker.GetProcessedCount()
-		skippedFiles = walker.GetSkippedCount()
+		skipped = walker.GetSkippedFiles()
 	}
+	skippedFiles := len(skipped)
 
 	// Calculate elapsed time
 	elapsed := time.Since(startTime)
 
+	// Generated and minified files are reported apart from the totals
+	counted, groups := SplitExcluded(fileStats, config.IncludeGenerated, config.IncludeMinified)
+
+	// Copies of a file are reported, and optionally counted only once
+	var duplicates []DuplicateGroup
+	if config.Duplicates || config.Dedupe {
+		duplicates = FindDuplicates(counted)
+	}
+	if config.Dedupe {
+		counted = Deduplicate(counted, duplicates)
+	}
+	if !config.Duplicates {
+		duplicates = nil
+	}
+
 	// Aggregate statistics
-	langStats := AggregateStats(fileStats)
+	langStats := AggregateStats(counted)
 	total := TotalStats(langStats)
-	errorCount := len(errors)
+	ReleaseLineHashes(fileStats)
+	report := &Report{
+		LangStats:      langStats,
+		Total:          total,
+		ProcessedFiles: processedFiles,
+		SkippedFiles:   skippedFiles,
+		ErrorCount:     len(errors),
+		ComplexFiles:   MostComplexFiles(counted, config.ComplexFiles),
+		Duplicates:     duplicates,
+		Groups:         groups,
+	}
+	if config.Cocomo {
+		report.Estimate = EstimateCost(total, config.Cost)
+	}
+	if config.Breakdown {
+		report.Breakdown = Breakdown(langStats)
+	}
+	if config.AnnotationList {
+		report.Annotations = ListAnnotations(counted)
+	}
 
 	// Output results based on format
 	switch config.OutputFormat {
 	case "json":
-		PrintJSON(langStats, total)
+		PrintJSON(report)
 	case "compact":
-		PrintCompact(total)
-	case "formatted":
-		PrintResultsFormatted(langStats, total, processedFiles, skippedFiles, errorCount)
+		PrintCompact(report)
 	default:
-		PrintResults(langStats, total, processedFiles, skippedFiles, errorCount)
+		PrintReport(report, config.OutputFormat == "formatted")
 	}
 
-	// Show errors if requested
-	if config.ShowErrors && len(errors) > 0 {
+	// Show errors and per-file warnings if requested
+	if config.ShowErrors {
 		PrintErrors(errors)
+		PrintDiagnostics(fileStats)
+		PrintSkipped(skipped)
 	}
 
-	// Print timing information
+	// Print timing information to stderr, after the results on stdout
 	if !config.Quiet {
-		fmt.Printf("Time elapsed: %v\n", elapsed.Round(time.Millisecond))
+		fmt.Fprintf(os.Stderr, "Time elapsed: %v\n", elapsed.Round(time.Millisecond))
 	}
 
 	return nil
@@ -158,8 +229,8 @@ func parseFlags() *Config {
 	flag.StringVar(&config.OutputFormat, "format", "default", "Output format: default, json, compact, formatted")
 	flag.StringVar(&config.OutputFormat, "f", "default", "Output format (shorthand)")
 
-	flag.BoolVar(&config.ShowErrors, "errors", false, "Show detailed error messages")
-	flag.BoolVar(&config.ShowErrors, "e", false, "Show detailed error messages (shorthand)")
+	flag.BoolVar(&config.ShowErrors, "errors", false, "Show detailed error and warning messages")
+	flag.BoolVar(&config.ShowErrors, "e", false, "Show detailed error and warning messages (shorthand)")
 
 	flag.BoolVar(&config.Verbose, "verbose", false, "Enable verbose output")
 	flag.BoolVar(&config.Verbose, "v", false, "Enable verbose output (shorthand)")
@@ -167,6 +238,47 @@ func parseFlags() *Config {
 	flag.BoolVar(&config.Quiet, "quiet", false, "Suppress non-essential output")
 	flag.BoolVar(&config.Quiet, "q", false, "Suppress non-essential output (shorthand)")
 
+	flag.BoolVar(&config.MarkdownCode, "markdown-code", false, "Count fenced code blocks in Markdown as their own languages")
+	flag.BoolVar(&config.MarkdownCode, "m", false, "Count fenced code blocks in Markdown as their own languages (shorthand)")
+
+	flag.BoolVar(&config.DisabledCode, "disabled-code", false, "Count #if 0 blocks in C-family files as disabled comments")
+	flag.BoolVar(&config.DisabledCode, "D", false, "Count #if 0 blocks in C-family files as disabled comments (shorthand)")
+
+	flag.IntVar(&config.ComplexFiles, "complex", 0, "List the n most complex files")
+	flag.IntVar(&config.ComplexFiles, "c", 0, "List the n most complex files (shorthand)")
+
+	flag.BoolVar(&config.Breakdown, "breakdown", false, "Show each language's share of the code base by bytes")
+	flag.BoolVar(&config.Breakdown, "b", false, "Show each language's share of the code base by bytes (shorthand)")
+
+	flag.BoolVar(&config.Duplicates, "duplicates", false, "List groups of files with identical contents")
+	flag.BoolVar(&config.Duplicates, "d", false, "List groups of files with identical contents (shorthand)")
+
+	flag.BoolVar(&config.Dedupe, "dedupe", false, "Count each group of identical files only once")
+	flag.BoolVar(&config.Dedupe, "u", false, "Count each group of identical files only once (shorthand)")
+
+	flag.BoolVar(&config.Cocomo, "cocomo", false, "Estimate the effort and cost to develop the code with COCOMO")
+	flag.BoolVar(&config.Cocomo, "C", false, "Estimate the effort and cost to develop the code with COCOMO (shorthand)")
+
+	// COCOMO estimate parameters
+	flag.StringVar(&config.Cost.ProjectType, "project-type", DefaultProjectType, "COCOMO project type: organic, semi-detached, embedded")
+	flag.Float64Var(&config.Cost.Wage, "wage", DefaultWage, "Average annual salary used for the COCOMO cost")
+	flag.Float64Var(&config.Cost.Overhead, "overhead", DefaultOverhead, "Overhead multiplier used for the COCOMO cost")
+
+	// Annotation markers in comments
+	var annotate bool
+	var annotationMarkers string
+	flag.BoolVar(&annotate, "annotations", false, "Count TODO, FIXME, HACK, XXX and BUG markers in comments")
+	flag.BoolVar(&annotate, "a", false, "Count TODO, FIXME, HACK, XXX and BUG markers in comments (shorthand)")
+	flag.BoolVar(&config.AnnotationList, "annotation-list", false, "List the location and text of each annotation marker")
+	flag.BoolVar(&config.AnnotationList, "A", false, "List the location and text of each annotation marker (shorthand)")
+	flag.StringVar(&annotationMarkers, "annotation-markers", "", "Comma-separated annotation markers to count instead of the defaults")
+
+	flag.BoolVar(&config.IncludeGenerated, "include-generated", false, "Count generated files in the main totals")
+	flag.BoolVar(&config.IncludeGenerated, "g", false, "Count generated files in the main totals (shorthand)")
+
+	flag.BoolVar(&config.IncludeMinified, "include-minified", false, "Count minified files in the main totals")
+	flag.BoolVar(&config.IncludeMinified, "M", false, "Count minified files in the main totals (shorthand)")
+
 	// Custom exclude directories
 	var excludeDirs string
 	flag.StringVar(&excludeDirs, "exclude", "", "Comma-separated list of directories to exclude")
@@ -177,6 +289,11 @@ func parseFlags() *Config {
 	flag.StringVar(&excludePatterns, "ignore", "", "Comma-separated list of patterns to exclude files (e.g., \"*_test.go,*.log\")")
 	flag.StringVar(&excludePatterns, "i", "", "Comma-separated list of patterns to exclude files (shorthand)")
 
+	// Default languages for ambiguous extensions
+	var fallbacks string
+	flag.StringVar(&fallbacks, "fallback", "", "Comma-separated ext=Language defaults for ambiguous extensions (e.g., \".h=C++ Header,.m=MATLAB\")")
+	flag.StringVar(&fallbacks, "F", "", "Comma-separated ext=Language defaults for ambiguous extensions (shorthand)")
+
 	// Version flag
 	version := flag.Bool("version", false, "Print version information")
 	versionShort := flag.Bool("V", false, "Print version information (shorthand)")
@@ -214,6 +331,18 @@ func parseFlags() *Config {
 		config.ExcludePatterns = splitAndTrim(excludePatterns, ",")
 	}
 
+	// Parse annotation markers, which enable counting them
+	if annotationMarkers != "" {
+		config.Annotations = splitAndTrim(annotationMarkers, ",")
+	} else if annotate {
+		config.Annotations = DefaultAnnotations
+	}
+
+	// Parse ambiguous extension fallbacks
+	if fallbacks != "" {
+		config.Fallbacks = parseFallbacks(fallbacks)
+	}
+
 	// Handle positional argument (path)
 	args := flag.Args()
 	if len(args) > 0 {
@@ -236,9 +365,25 @@ Options:
   -f, --format <format>   Output format: default, json, compact, formatted
   -x, --exclude <dirs>    Comma-separated list of directories to exclude
   -i, --ignore <patterns> Comma-separated list of patterns to exclude files
-  -e, --errors            Show detailed error messages
+  -e, --errors            Show detailed error and warning messages
   -v, --verbose           Enable verbose output
   -q, --quiet             Suppress non-essential output
+  -m, --markdown-code     Count fenced code blocks in Markdown as their own languages
+  -D, --disabled-code     Count #if 0 blocks in C-family files as disabled comments
+  -c, --complex <n>       List the n most complex files
+  -b, --breakdown         Show each language's share of the code base by bytes
+  -d, --duplicates        List groups of files with identical contents
+  -u, --dedupe            Count each group of identical files only once
+  -C, --cocomo            Estimate the effort and cost to develop the code with COCOMO
+      --project-type <t>  COCOMO project type: organic, semi-detached, embedded (default: organic)
+      --wage <n>          Average annual salary for the COCOMO cost (default: 56286)
+      --overhead <n>      Overhead multiplier for the COCOMO cost (default: 2.4)
+  -a, --annotations       Count TODO, FIXME, HACK, XXX and BUG markers in comments
+  -A, --annotation-list   List the location and text of each annotation marker
+      --annotation-markers <words> Comma-separated markers to count instead of the defaults
+  -F, --fallback <pairs>  Comma-separated ext=Language defaults for ambiguous extensions
+  -g, --include-generated Count generated files in the main totals
+  -M, --include-minified  Count minified files in the main totals
   -V, --version           Print version information
   -h, --help              Print this help message
 
@@ -249,6 +394,13 @@ Examples:
   %s -w 8 -H .            Use 8 workers and include hidden files
   %s -x "test,docs" .     Exclude test and docs directories
   %s -i "users_*.go,*log" . Exclude files matching patterns
+  %s -m docs              Count code examples in Markdown docs separately
+  %s -F ".h=C++ Header" . Treat headers without clear markers as C++
+  %s -c 20 src            List the 20 files with the most branches
+  %s -b .                 Show each language's share by bytes
+  %s -d -u .              List copied files and count each only once
+  %s -C --project-type embedded . Estimate the cost of an embedded project
+  %s -A --annotation-markers "TODO,NOTE" . List TODO and NOTE comments
 
 Supported Languages:
   Go, JavaScript, TypeScript, Python, Java, C, C++, C#, Ruby, PHP,
@@ -257,7 +409,7 @@ Supported Languages:
   Haskell, Clojure, TOML, INI, Terraform, Protocol Buffers, GraphQL,
   Assembly
 
-`, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName)
+`, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName)
 }
 
 func splitAndTrim(s string, sep string) []string {
@@ -284,6 +436,24 @@ func splitAndTrim(s string, sep string) []string {
 	return parts
 }
 
+// parseFallbacks parses ext=Language pairs, normalising each extension to
+// lower case with a leading dot
+func parseFallbacks(s string) map[string]string {
+	fallbacks := make(map[string]string)
+	for _, pair := range splitAndTrim(s, ",") {
+		ext, name, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		ext = strings.ToLower(trimSpace(ext))
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		fallbacks[ext] = trimSpace(name)
+	}
+	return fallbacks
+}
+
 func trimSpace(s string) string {
 	start := 0
 	end := len(s)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"os"
 	"path/filepath"
@@ -64,6 +65,14 @@ func TestSplitAndTrim(t *testing.T) {
 	}
 }
 
+func TestParseFallbacks(t *testing.T) {
+	got := parseFallbacks(".h=C++ Header, M = MATLAB,invalid")
+	want := map[string]string{".h": "C++ Header", ".m": "MATLAB"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseFallbacks() = %v, want %v", got, want)
+	}
+}
+
 func TestParseFlags(t *testing.T) {
 	// Save original args and flag set
 	oldArgs := os.Args
@@ -177,6 +186,36 @@ func TestRun(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "COCOMO estimate",
+			config: &Config{
+				Path:         tmpDir,
+				OutputFormat: "json",
+				Quiet:        true,
+				Cocomo:       true,
+				Cost:         CostOptions{ProjectType: "semi-detached", Wage: DefaultWage, Overhead: DefaultOverhead},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Unknown COCOMO project type",
+			config: &Config{
+				Path:   tmpDir,
+				Cocomo: true,
+				Cost:   CostOptions{ProjectType: "agile", Wage: DefaultWage, Overhead: DefaultOverhead},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Annotation list",
+			config: &Config{
+				Path:           tmpDir,
+				OutputFormat:   "compact",
+				Quiet:          true,
+				AnnotationList: true,
+			},
+			wantErr: false,
+		},
 		{
 			name: "Show errors",
 			config: &Config{
@@ -206,3 +245,43 @@ func TestPrintUsage(t *testing.T) {
 		t.Errorf("printUsage output missing 'Usage:'")
 	}
 }
+
+func TestRunJSONWithErrors(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "main-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	os.WriteFile(filepath.Join(tmpDir, "test.go"), []byte("package main"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "blob.bin"), []byte{0, 1, 2}, 0644)
+	os.WriteFile(filepath.Join(tmpDir, "stray.go"), []byte("var s = \"open\n"), 0644)
+
+	config := &Config{
+		Path:         tmpDir,
+		OutputFormat: "json",
+		ShowErrors:   true,
+		Verbose:      true,
+	}
+	var warnings string
+	output := captureStdout(func() {
+		warnings = captureStderr(func() {
+			if err := Run(config); err != nil {
+				t.Errorf("Run() error = %v", err)
+			}
+		})
+	})
+
+	if !json.Valid([]byte(output)) {
+		t.Errorf("Run() printed invalid JSON: %s", output)
+	}
+	if !strings.Contains(warnings, "binary extension: 1") {
+		t.Errorf("Skipped files missing from stderr: %s", warnings)
+	}
+	if !strings.Contains(warnings, "unterminated string literal") {
+		t.Errorf("Diagnostics missing from stderr: %s", warnings)
+	}
+	if !strings.Contains(warnings, "Time elapsed") {
+		t.Errorf("Timing missing from stderr: %s", warnings)
+	}
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"os"
 	"sort"
 	"strings"
 )
@@ -12,10 +15,67 @@ const (
 	colFiles    = 10
 	colBlank    = 12
 	colComment  = 12
+	colDoc      = 12
+	colDisabled = 12
 	colCode     = 12
+	colMixed    = 12
 	colTotal    = 12
+
+	colComplexity = 12
+	colBytes      = 14
+	colUnique     = 12
+	colLogical    = 12
+	colCharacters = 14
+
+	// Declarations table
+	colFunctions  = 12
+	colTypes      = 12
+	colInterfaces = 12
+	colAvgLength  = 14
+
+	// Language breakdown table
+	colShare = 10
+
+	// Annotations table, widened for longer markers
+	colAnnotation = 10
 )
 
+// Report holds the statistics of a run and the optional reports requested
+// with them, for the printers of each output format
+type Report struct {
+	LangStats map[string]*LanguageStats
+	Total     *LanguageStats
+
+	ProcessedFiles int
+	SkippedFiles   int
+	ErrorCount     int
+
+	Estimate     *Estimate
+	ComplexFiles []*FileStats
+	Breakdown    []LanguageShare
+	Duplicates   []DuplicateGroup
+	Annotations  []Annotation
+	Groups       []FileGroup
+}
+
+// PrintReport prints the results table followed by the table or list of
+// each optional report, with thousand separators if formatted is set
+func PrintReport(report *Report, formatted bool) {
+	if formatted {
+		PrintResultsFormatted(report.LangStats, report.Total, report.ProcessedFiles, report.SkippedFiles, report.ErrorCount)
+	} else {
+		PrintResults(report.LangStats, report.Total, report.ProcessedFiles, report.SkippedFiles, report.ErrorCount)
+	}
+	PrintDeclarations(report.LangStats, report.Total, formatted)
+	PrintEstimate(report.Estimate, formatted)
+	PrintAnnotations(report.LangStats, report.Total, formatted)
+	PrintBreakdown(report.Breakdown, formatted)
+	PrintComplexFiles(report.ComplexFiles, formatted)
+	PrintDuplicates(report.Duplicates)
+	PrintAnnotationList(report.Annotations)
+	PrintGroups(report.Groups, formatted)
+}
+
 // PrintResults prints the results in a formatted table
 func PrintResults(langStats map[string]*LanguageStats, total *LanguageStats, processedFiles, skippedFiles, errorCount int) {
 	// Print header
@@ -27,14 +87,14 @@ func PrintResults(langStats map[string]*LanguageStats, total *LanguageStats, pro
 	// Print each language row
 	for _, lang := range sortedLangs {
 		stats := langStats[lang]
-		printRow(stats.Language, stats.FileCount, stats.BlankLines, stats.CommentLines, stats.CodeLines, stats.TotalLines)
+		printRow(stats.Language, stats)
 	}
 
 	// Print separator
 	printSeparator()
 
 	// Print total row
-	printRow("Total", total.FileCount, total.BlankLines, total.CommentLines, total.CodeLines, total.TotalLines)
+	printRow("Total", total)
 
 	// Print footer with summary
 	printFooter(processedFiles, skippedFiles, errorCount)
@@ -44,36 +104,62 @@ func PrintResults(langStats map[string]*LanguageStats, total *LanguageStats, pro
 func printHeader() {
 	fmt.Println()
 	printSeparator()
-	fmt.Printf("%-*s %*s %*s %*s %*s %*s\n",
+	fmt.Printf("%-*s %*s %*s %*s %*s %*s %*s %*s %*s %*s %*s %*s %*s %*s\n",
 		colLanguage, "Language",
 		colFiles, "Files",
 		colBlank, "Blank",
 		colComment, "Comment",
+		colDoc, "Doc",
+		colDisabled, "Disabled",
 		colCode, "Code",
-		colTotal, "Total")
+		colLogical, "LLOC",
+		colMixed, "Mixed",
+		colUnique, "ULOC",
+		colTotal, "Total",
+		colBytes, "Bytes",
+		colCharacters, "Characters",
+		colComplexity, "Complexity")
 	printSeparator()
 }
 
 // printSeparator prints a separator line
 func printSeparator() {
-	totalWidth := colLanguage + colFiles + colBlank + colComment + colCode + colTotal + 5 // 5 spaces between columns
+	totalWidth := colLanguage + colFiles + colBlank + colComment + colDoc + colDisabled + colCode + colLogical + colMixed + colUnique + colTotal + colBytes + colCharacters + colComplexity + 13 // 13 spaces between columns
 	fmt.Println(strings.Repeat("-", totalWidth))
 }
 
 // printRow prints a single row of the table
-func printRow(language string, files, blank, comment, code, total int) {
+func printRow(language string, stats *LanguageStats) {
+	printCells(language, stats, func(n int) string { return fmt.Sprintf("%d", n) })
+}
+
+// printFormattedRow prints a single row of the table with thousand separators
+func printFormattedRow(language string, stats *LanguageStats) {
+	printCells(language, stats, FormatNumber)
+}
+
+// printCells prints the cells of a row, rendering numbers with format
+func printCells(language string, stats *LanguageStats, format func(int) string) {
 	// Truncate language name if too long
 	if len(language) > colLanguage {
 		language = language[:colLanguage-3] + "..."
 	}
 
-	fmt.Printf("%-*s %*d %*d %*d %*d %*d\n",
+	fmt.Printf("%-*s %*s %*s %*s %*s %*s %*s %*s %*s %*s %*s %*s %*s %*s\n",
 		colLanguage, language,
-		colFiles, files,
-		colBlank, blank,
-		colComment, comment,
-		colCode, code,
-		colTotal, total)
+		colFiles, format(stats.FileCount),
+		colBlank, format(stats.BlankLines),
+		colComment, format(stats.CommentLines),
+		colDoc, format(stats.DocLines),
+		colDisabled, format(stats.DisabledLines),
+		colCode, format(stats.CodeLines),
+		colLogical, format(stats.LogicalLines),
+		colMixed, format(stats.MixedLines),
+		colUnique, format(stats.UniqueLines),
+		colTotal, format(stats.TotalLines),
+		colBytes, format(stats.Bytes),
+		colCharacters, format(stats.Characters),
+		colComplexity, format(stats.Complexity))
 }
 
 // printFooter prints the summary footer
@@ -103,33 +189,221 @@ func sortLanguagesByCode(langStats map[string]*LanguageStats) []string {
 	return langs
 }
 
-// PrintErrors prints the list of errors encountered
+// PrintErrors prints the list of errors encountered to stderr
 func PrintErrors(errors []error) {
 	if len(errors) == 0 {
 		return
 	}
 
-	fmt.Println("\nErrors encountered:")
+	fmt.Fprintln(os.Stderr, "\nErrors encountered:")
 	for i, err := range errors {
 		if i >= 10 {
-			fmt.Printf("  ... and %d more errors\n", len(errors)-10)
+			fmt.Fprintf(os.Stderr, "  ... and %d more errors\n", len(errors)-10)
 			break
 		}
-		fmt.Printf("  - %v\n", err)
+		fmt.Fprintf(os.Stderr, "  - %v\n", err)
 	}
-	fmt.Println()
+	fmt.Fprintln(os.Stderr)
 }
 
-// PrintCompact prints a compact summary
-func PrintCompact(total *LanguageStats) {
-	fmt.Printf("Files: %d | Blank: %d | Comment: %d | Code: %d | Total: %d\n",
-		total.FileCount, total.BlankLines, total.CommentLines, total.CodeLines, total.TotalLines)
+// PrintDiagnostics prints the per-file diagnostics of files whose counts may
+// be inaccurate to stderr, keeping them out of results on stdout
+func PrintDiagnostics(fileStats []*FileStats) {
+	var lines []string
+	for _, fs := range fileStats {
+		if fs == nil {
+			continue
+		}
+		for _, d := range fs.Diagnostics {
+			lines = append(lines, fmt.Sprintf("%s:%d: %s", fs.FilePath, d.Line, d.Message))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "\nWarnings:")
+	for i, line := range lines {
+		if i >= 10 {
+			fmt.Fprintf(os.Stderr, "  ... and %d more warnings\n", len(lines)-10)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "  - %s\n", line)
+	}
+	fmt.Fprintln(os.Stderr)
 }
 
-// PrintJSON prints results in JSON format
-func PrintJSON(langStats map[string]*LanguageStats, total *LanguageStats) {
+// PrintSkipped prints how many files were skipped for each reason to stderr,
+// keeping it out of JSON and other results on stdout
+func PrintSkipped(skipped []SkippedFile) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, file := range skipped {
+		counts[file.Reason]++
+	}
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	fmt.Fprintln(os.Stderr, "\nSkipped files:")
+	for _, reason := range reasons {
+		fmt.Fprintf(os.Stderr, "  - %s: %d\n", reason, counts[reason])
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
+// PrintCompact prints a compact summary, followed by the cost estimate, the
+// annotation counts, the language breakdown, one line for each of the most
+// complex files, a count of duplicate files, one line for each annotation and
+// one line for each group of files excluded from the summary
+func PrintCompact(report *Report) {
+	total := report.Total
+	fmt.Printf("Files: %d | Blank: %d | Comment: %d | Doc: %d | Disabled: %d | Code: %d | LLOC: %d | Mixed: %d | ULOC: %d | Total: %d | Bytes: %d | Characters: %d | Complexity: %d\n",
+		total.FileCount, total.BlankLines, total.CommentLines, total.DocLines, total.DisabledLines, total.CodeLines, total.LogicalLines, total.MixedLines, total.UniqueLines, total.TotalLines, total.Bytes, total.Characters, total.Complexity)
+	fmt.Printf("Functions: %d | Types: %d | Interfaces: %d | Avg Function Length: %.1f\n",
+		total.Functions, total.Types, total.Interfaces, total.AverageFunctionLength())
+	if estimate := report.Estimate; estimate != nil {
+		fmt.Printf("COCOMO (%s): Effort: %.2f person-months | Schedule: %.2f months | People: %.2f | Cost: %.0f\n",
+			estimate.ProjectType, estimate.Effort, estimate.Schedule, estimate.People, estimate.Cost)
+	}
+	if len(total.Annotations) > 0 {
+		markers := sortedMarkers(total.Annotations)
+		parts := make([]string, len(markers))
+		for i, marker := range markers {
+			parts[i] = fmt.Sprintf("%s %d", marker, total.Annotations[marker])
+		}
+		fmt.Printf("Annotations: %s\n", strings.Join(parts, " | "))
+	}
+	if len(report.Breakdown) > 0 {
+		parts := make([]string, len(report.Breakdown))
+		for i, share := range report.Breakdown {
+			parts[i] = fmt.Sprintf("%s %.1f%%", share.Language, share.Percent)
+		}
+		fmt.Printf("Breakdown: %s\n", strings.Join(parts, " | "))
+	}
+	for _, fs := range report.ComplexFiles {
+		fmt.Printf("Complex: %s | Complexity: %d | Code: %d\n", fs.FilePath, fileComplexity(fs), fs.CodeLines)
+	}
+	if len(report.Duplicates) > 0 {
+		copies := 0
+		for _, group := range report.Duplicates {
+			copies += len(group.Files) - 1
+		}
+		fmt.Printf("Duplicates: %d groups | %d copies\n", len(report.Duplicates), copies)
+	}
+	for _, a := range report.Annotations {
+		fmt.Printf("Annotation: %s:%d: %s\n", a.Path, a.Line, a.Text)
+	}
+	for _, group := range report.Groups {
+		fmt.Printf("%s (excluded): Files: %d | Code: %d | Total: %d\n",
+			group.Name, group.Total.FileCount, group.Total.CodeLines, group.Total.TotalLines)
+	}
+}
+
+// PrintJSON prints results in JSON format. The cost estimate is reported under
+// "cocomo", the most complex files are listed under "complex_files", the
+// language breakdown under "breakdown", groups of duplicate files under
+// "duplicates", annotations under "annotation_list" and groups of files
+// excluded from the totals under "excluded".
+func PrintJSON(report *Report) {
 	fmt.Println("{")
-	fmt.Println("  \"languages\": {")
+	printJSONLanguages("  ", report.LangStats)
+	fmt.Printf("  \"total\": %s", jsonStats(report.Total))
+
+	if estimate := report.Estimate; estimate != nil {
+		fmt.Println(",")
+		fmt.Printf("  \"cocomo\": {\"project_type\": %s, \"effort\": %.2f, \"schedule\": %.2f, \"people\": %.2f, \"cost\": %.2f}",
+			jsonString(estimate.ProjectType), estimate.Effort, estimate.Schedule, estimate.People, estimate.Cost)
+	}
+
+	if len(report.ComplexFiles) > 0 {
+		fmt.Println(",")
+		fmt.Println("  \"complex_files\": [")
+		for i, fs := range report.ComplexFiles {
+			comma := ","
+			if i == len(report.ComplexFiles)-1 {
+				comma = ""
+			}
+			fmt.Printf("    {\"path\": %s, \"language\": %s, \"complexity\": %d, \"code\": %d}%s\n",
+				jsonString(fs.FilePath), jsonString(fs.Language), fileComplexity(fs), fs.CodeLines, comma)
+		}
+		fmt.Print("  ]")
+	}
+
+	if len(report.Breakdown) > 0 {
+		fmt.Println(",")
+		fmt.Println("  \"breakdown\": [")
+		for i, share := range report.Breakdown {
+			comma := ","
+			if i == len(report.Breakdown)-1 {
+				comma = ""
+			}
+			fmt.Printf("    {\"language\": %s, \"bytes\": %d, \"percent\": %.1f}%s\n",
+				jsonString(share.Language), share.Bytes, share.Percent, comma)
+		}
+		fmt.Print("  ]")
+	}
+
+	if len(report.Duplicates) > 0 {
+		fmt.Println(",")
+		fmt.Println("  \"duplicates\": [")
+		for i, group := range report.Duplicates {
+			paths := make([]string, len(group.Files))
+			for j, fs := range group.Files {
+				paths[j] = jsonString(fs.FilePath)
+			}
+			comma := ","
+			if i == len(report.Duplicates)-1 {
+				comma = ""
+			}
+			fmt.Printf("    {\"code\": %d, \"files\": [%s]}%s\n", group.Files[0].CodeLines, strings.Join(paths, ", "), comma)
+		}
+		fmt.Print("  ]")
+	}
+
+	if len(report.Annotations) > 0 {
+		fmt.Println(",")
+		fmt.Println("  \"annotation_list\": [")
+		for i, a := range report.Annotations {
+			comma := ","
+			if i == len(report.Annotations)-1 {
+				comma = ""
+			}
+			fmt.Printf("    {\"path\": %s, \"line\": %d, \"marker\": %s, \"text\": %s}%s\n",
+				jsonString(a.Path), a.Line, jsonString(a.Marker), jsonString(a.Text), comma)
+		}
+		fmt.Print("  ]")
+	}
+
+	if len(report.Groups) > 0 {
+		fmt.Println(",")
+		fmt.Println("  \"excluded\": {")
+		for i, group := range report.Groups {
+			fmt.Printf("    \"%s\": {\n", strings.ToLower(group.Name))
+			printJSONLanguages("      ", group.LangStats)
+			fmt.Printf("      \"total\": %s\n", jsonStats(group.Total))
+			comma := ","
+			if i == len(report.Groups)-1 {
+				comma = ""
+			}
+			fmt.Printf("    }%s\n", comma)
+		}
+		fmt.Print("  }")
+	}
+
+	fmt.Println()
+	fmt.Println("}")
+}
+
+// printJSONLanguages prints the "languages" member of a JSON object, sorted
+// by code lines
+func printJSONLanguages(indent string, langStats map[string]*LanguageStats) {
+	fmt.Printf("%s\"languages\": {\n", indent)
 
 	sortedLangs := sortLanguagesByCode(langStats)
 	for i, lang := range sortedLangs {
@@ -138,14 +412,280 @@ func PrintJSON(langStats map[string]*LanguageStats, total *LanguageStats) {
 		if i == len(sortedLangs)-1 {
 			comma = ""
 		}
-		fmt.Printf("    \"%s\": {\"files\": %d, \"blank\": %d, \"comment\": %d, \"code\": %d, \"total\": %d}%s\n",
-			stats.Language, stats.FileCount, stats.BlankLines, stats.CommentLines, stats.CodeLines, stats.TotalLines, comma)
+		fmt.Printf("%s  \"%s\": %s%s\n", indent, stats.Language, jsonStats(stats), comma)
 	}
 
-	fmt.Println("  },")
-	fmt.Printf("  \"total\": {\"files\": %d, \"blank\": %d, \"comment\": %d, \"code\": %d, \"total\": %d}\n",
-		total.FileCount, total.BlankLines, total.CommentLines, total.CodeLines, total.TotalLines)
-	fmt.Println("}")
+	fmt.Printf("%s},\n", indent)
+}
+
+// PrintGroups prints a table for each group of files excluded from the
+// totals, such as generated code
+func PrintGroups(groups []FileGroup, formatted bool) {
+	row := printRow
+	if formatted {
+		row = printFormattedRow
+	}
+
+	for _, group := range groups {
+		fmt.Printf("%s files (excluded from totals):\n", group.Name)
+		printSeparator()
+		for _, lang := range sortLanguagesByCode(group.LangStats) {
+			stats := group.LangStats[lang]
+			row(stats.Language, stats)
+		}
+		printSeparator()
+		row("Total", group.Total)
+		printSeparator()
+		fmt.Println()
+	}
+}
+
+// jsonStats renders the statistics of a language as a JSON object
+func jsonStats(stats *LanguageStats) string {
+	s := fmt.Sprintf("{\"files\": %d, \"blank\": %d, \"comment\": %d, \"doc\": %d, \"disabled\": %d, \"code\": %d, \"lloc\": %d, \"mixed\": %d, \"uloc\": %d, \"total\": %d, \"bytes\": %d, \"characters\": %d, \"complexity\": %d, "+
+		"\"functions\": %d, \"types\": %d, \"interfaces\": %d, \"avg_function_length\": %.1f",
+		stats.FileCount, stats.BlankLines, stats.CommentLines, stats.DocLines, stats.DisabledLines, stats.CodeLines, stats.LogicalLines, stats.MixedLines, stats.UniqueLines, stats.TotalLines, stats.Bytes, stats.Characters, stats.Complexity,
+		stats.Functions, stats.Types, stats.Interfaces, stats.AverageFunctionLength())
+
+	// Annotation counts are only present when annotations were counted
+	if len(stats.Annotations) > 0 {
+		markers := sortedMarkers(stats.Annotations)
+		counts := make([]string, len(markers))
+		for i, marker := range markers {
+			counts[i] = fmt.Sprintf("%s: %d", jsonString(marker), stats.Annotations[marker])
+		}
+		s += fmt.Sprintf(", \"annotations\": {%s}", strings.Join(counts, ", "))
+	}
+	return s + "}"
+}
+
+// jsonString renders s as a JSON string, escaping it as needed
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// PrintDeclarations prints a table of the functions, types and interfaces
+// declared in each language that has any, sorted by function count
+func PrintDeclarations(langStats map[string]*LanguageStats, total *LanguageStats, formatted bool) {
+	if total.Functions+total.Types+total.Interfaces == 0 {
+		return
+	}
+
+	format := func(n int) string { return fmt.Sprintf("%d", n) }
+	if formatted {
+		format = FormatNumber
+	}
+
+	langs := make([]string, 0, len(langStats))
+	for lang, stats := range langStats {
+		if stats.Functions+stats.Types+stats.Interfaces > 0 {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		return langStats[langs[i]].Functions > langStats[langs[j]].Functions
+	})
+
+	width := colLanguage + colFunctions + colTypes + colInterfaces + colAvgLength + 4 // 4 spaces between columns
+	row := func(language string, stats *LanguageStats) {
+		if len(language) > colLanguage {
+			language = language[:colLanguage-3] + "..."
+		}
+		fmt.Printf("%-*s %*s %*s %*s %*.1f\n",
+			colLanguage, language,
+			colFunctions, format(stats.Functions),
+			colTypes, format(stats.Types),
+			colInterfaces, format(stats.Interfaces),
+			colAvgLength, stats.AverageFunctionLength())
+	}
+
+	fmt.Println("Declarations:")
+	fmt.Println(strings.Repeat("-", width))
+	fmt.Printf("%-*s %*s %*s %*s %*s\n",
+		colLanguage, "Language",
+		colFunctions, "Functions",
+		colTypes, "Types",
+		colInterfaces, "Interfaces",
+		colAvgLength, "Avg Fn Lines")
+	fmt.Println(strings.Repeat("-", width))
+	for _, lang := range langs {
+		row(lang, langStats[lang])
+	}
+	fmt.Println(strings.Repeat("-", width))
+	row("Total", total)
+	fmt.Println(strings.Repeat("-", width))
+	fmt.Println()
+}
+
+// PrintAnnotations prints a table of the annotation markers, such as TODO,
+// found in the comments of each language that has any, sorted by their count
+func PrintAnnotations(langStats map[string]*LanguageStats, total *LanguageStats, formatted bool) {
+	if len(total.Annotations) == 0 {
+		return
+	}
+
+	format := func(n int) string { return fmt.Sprintf("%d", n) }
+	if formatted {
+		format = FormatNumber
+	}
+
+	count := func(stats *LanguageStats) int {
+		n := 0
+		for _, c := range stats.Annotations {
+			n += c
+		}
+		return n
+	}
+	langs := make([]string, 0, len(langStats))
+	for lang, stats := range langStats {
+		if len(stats.Annotations) > 0 {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		ci, cj := count(langStats[langs[i]]), count(langStats[langs[j]])
+		if ci != cj {
+			return ci > cj
+		}
+		return langs[i] < langs[j]
+	})
+
+	markers := sortedMarkers(total.Annotations)
+	widths := make([]int, len(markers))
+	width := colLanguage
+	for i, marker := range markers {
+		widths[i] = max(colAnnotation, len(marker))
+		width += widths[i] + 1
+	}
+	row := func(language string, cells func(i int) string) {
+		if len(language) > colLanguage {
+			language = language[:colLanguage-3] + "..."
+		}
+		fmt.Printf("%-*s", colLanguage, language)
+		for i := range markers {
+			fmt.Printf(" %*s", widths[i], cells(i))
+		}
+		fmt.Println()
+	}
+	counts := func(stats *LanguageStats) func(i int) string {
+		return func(i int) string { return format(stats.Annotations[markers[i]]) }
+	}
+
+	fmt.Println("Annotations:")
+	fmt.Println(strings.Repeat("-", width))
+	row("Language", func(i int) string { return markers[i] })
+	fmt.Println(strings.Repeat("-", width))
+	for _, lang := range langs {
+		row(lang, counts(langStats[lang]))
+	}
+	fmt.Println(strings.Repeat("-", width))
+	row("Total", counts(total))
+	fmt.Println(strings.Repeat("-", width))
+	fmt.Println()
+}
+
+// PrintAnnotationList lists each annotation with its location and comment
+// text
+func PrintAnnotationList(annotations []Annotation) {
+	if len(annotations) == 0 {
+		return
+	}
+
+	fmt.Println("Annotation locations:")
+	printSeparator()
+	for _, a := range annotations {
+		fmt.Printf("  %s:%d: %s\n", a.Path, a.Line, a.Text)
+	}
+	printSeparator()
+	fmt.Println()
+}
+
+// PrintBreakdown prints each language's share of the code base by size
+func PrintBreakdown(breakdown []LanguageShare, formatted bool) {
+	if len(breakdown) == 0 {
+		return
+	}
+
+	format := func(n int) string { return fmt.Sprintf("%d", n) }
+	if formatted {
+		format = FormatNumber
+	}
+
+	width := colLanguage + colBytes + colShare + 2 // 2 spaces between columns
+	fmt.Println("Language breakdown:")
+	fmt.Println(strings.Repeat("-", width))
+	fmt.Printf("%-*s %*s %*s\n", colLanguage, "Language", colBytes, "Bytes", colShare, "Share")
+	fmt.Println(strings.Repeat("-", width))
+	for _, share := range breakdown {
+		language := share.Language
+		if len(language) > colLanguage {
+			language = language[:colLanguage-3] + "..."
+		}
+		fmt.Printf("%-*s %*s %*.1f%%\n", colLanguage, language, colBytes, format(share.Bytes), colShare-1, share.Percent)
+	}
+	fmt.Println(strings.Repeat("-", width))
+	fmt.Println()
+}
+
+// PrintEstimate prints the basic COCOMO estimate of the effort and cost to
+// develop the code base
+func PrintEstimate(estimate *Estimate, formatted bool) {
+	if estimate == nil {
+		return
+	}
+
+	cost := fmt.Sprintf("%.0f", estimate.Cost)
+	if formatted {
+		cost = FormatNumber(int(math.Round(estimate.Cost)))
+	}
+
+	fmt.Printf("Estimated cost to develop (COCOMO, %s):\n", estimate.ProjectType)
+	printSeparator()
+	fmt.Printf("  Effort:   %.2f person-months\n", estimate.Effort)
+	fmt.Printf("  Schedule: %.2f months\n", estimate.Schedule)
+	fmt.Printf("  People:   %.2f\n", estimate.People)
+	fmt.Printf("  Cost:     %s\n", cost)
+	printSeparator()
+	fmt.Println()
+}
+
+// PrintDuplicates lists the groups of files with identical contents
+func PrintDuplicates(duplicates []DuplicateGroup) {
+	if len(duplicates) == 0 {
+		return
+	}
+
+	fmt.Println("Duplicate files:")
+	printSeparator()
+	for _, group := range duplicates {
+		fmt.Printf("%d copies, %d code lines each:\n", len(group.Files), group.Files[0].CodeLines)
+		for _, fs := range group.Files {
+			fmt.Printf("  %s\n", fs.FilePath)
+		}
+	}
+	printSeparator()
+	fmt.Println()
+}
+
+// PrintComplexFiles lists the most complex files, with their complexity
+func PrintComplexFiles(files []*FileStats, formatted bool) {
+	if len(files) == 0 {
+		return
+	}
+
+	format := func(n int) string { return fmt.Sprintf("%d", n) }
+	if formatted {
+		format = FormatNumber
+	}
+
+	fmt.Println("Most complex files:")
+	printSeparator()
+	for _, fs := range files {
+		fmt.Printf("%*s  %s (%s)\n", colComplexity, format(fileComplexity(fs)), fs.FilePath, fs.Language)
+	}
+	printSeparator()
+	fmt.Println()
 }
 
 // PrintByFiles prints results sorted by file count
@@ -165,14 +705,14 @@ func PrintByFiles(langStats map[string]*LanguageStats, total *LanguageStats, pro
 	// Print each language row
 	for _, lang := range langs {
 		stats := langStats[lang]
-		printRow(stats.Language, stats.FileCount, stats.BlankLines, stats.CommentLines, stats.CodeLines, stats.TotalLines)
+		printRow(stats.Language, stats)
 	}
 
 	// Print separator
 	printSeparator()
 
 	// Print total row
-	printRow("Total", total.FileCount, total.BlankLines, total.CommentLines, total.CodeLines, total.TotalLines)
+	printRow("Total", total)
 
 	// Print footer with summary
 	printFooter(processedFiles, skippedFiles, errorCount)
@@ -200,16 +740,7 @@ func FormatNumber(n int) string {
 
 // PrintResultsFormatted prints results with formatted numbers
 func PrintResultsFormatted(langStats map[string]*LanguageStats, total *LanguageStats, processedFiles, skippedFiles, errorCount int) {
-	fmt.Println()
-	printSeparator()
-	fmt.Printf("%-*s %*s %*s %*s %*s %*s\n",
-		colLanguage, "Language",
-		colFiles, "Files",
-		colBlank, "Blank",
-		colComment, "Comment",
-		colCode, "Code",
-		colTotal, "Total")
-	printSeparator()
+	printHeader()
 
 	// Sort languages by code lines (descending)
 	sortedLangs := sortLanguagesByCode(langStats)
@@ -217,29 +748,13 @@ func PrintResultsFormatted(langStats map[string]*LanguageStats, total *LanguageS
 	// Print each language row with formatted numbers
 	for _, lang := range sortedLangs {
 		stats := langStats[lang]
-		language := stats.Language
-		if len(language) > colLanguage {
-			language = language[:colLanguage-3] + "..."
-		}
-		fmt.Printf("%-*s %*s %*s %*s %*s %*s\n",
-			colLanguage, language,
-			colFiles, FormatNumber(stats.FileCount),
-			colBlank, FormatNumber(stats.BlankLines),
-			colComment, FormatNumber(stats.CommentLines),
-			colCode, FormatNumber(stats.CodeLines),
-			colTotal, FormatNumber(stats.TotalLines))
+		printFormattedRow(stats.Language, stats)
 	}
 
 	printSeparator()
 
 	// Print total row with formatted numbers
-	fmt.Printf("%-*s %*s %*s %*s %*s %*s\n",
-		colLanguage, "Total",
-		colFiles, FormatNumber(total.FileCount),
-		colBlank, FormatNumber(total.BlankLines),
-		colComment, FormatNumber(total.CommentLines),
-		colCode, FormatNumber(total.CodeLines),
-		colTotal, FormatNumber(total.TotalLines))
+	printFormattedRow("Total", total)
 
 	printFooter(processedFiles, skippedFiles, errorCount)
 }
@@ -9,28 +9,50 @@ import (
 func TestPrintResults(t *testing.T) {
 	langStats := map[string]*LanguageStats{
 		"Go": {
-			Language:     "Go",
-			FileCount:    1,
-			BlankLines:   10,
-			CommentLines: 20,
-			CodeLines:    70,
-			TotalLines:   100,
+			Language:      "Go",
+			FileCount:     1,
+			BlankLines:    10,
+			CommentLines:  20,
+			CodeLines:     70,
+			LogicalLines:  45,
+			TotalLines:    100,
+			MixedLines:    5,
+			DocLines:      8,
+			DisabledLines: 3,
+			Complexity:    12,
+			Functions:     4,
+			Types:         2,
+			FunctionLines: 30,
+			Bytes:         4096,
+			Characters:    4000,
+			UniqueLines:   60,
 		},
 	}
 	total := &LanguageStats{
-		Language:     "Total",
-		FileCount:    1,
-		BlankLines:   10,
-		CommentLines: 20,
-		CodeLines:    70,
-		TotalLines:   100,
+		Language:      "Total",
+		FileCount:     1,
+		BlankLines:    10,
+		CommentLines:  20,
+		CodeLines:     70,
+		LogicalLines:  45,
+		TotalLines:    100,
+		MixedLines:    5,
+		DocLines:      8,
+		DisabledLines: 3,
+		Complexity:    12,
+		Functions:     4,
+		Types:         2,
+		FunctionLines: 30,
+		Bytes:         4096,
+		Characters:    4000,
+		UniqueLines:   60,
 	}
 
 	t.Run("Default format", func(t *testing.T) {
 		output := captureStdout(func() {
 			PrintResults(langStats, total, 1, 0, 0)
 		})
-		if !strings.Contains(output, "Go") || !strings.Contains(output, "Total") {
+		if !strings.Contains(output, "Go") || !strings.Contains(output, "Total") || !strings.Contains(output, "Mixed") || !strings.Contains(output, "Doc") || !strings.Contains(output, "LLOC") || !strings.Contains(output, "Disabled") || !strings.Contains(output, "Characters") {
 			t.Errorf("Output missing expected content: %s", output)
 		}
 	})
@@ -39,25 +61,239 @@ func TestPrintResults(t *testing.T) {
 		output := captureStdout(func() {
 			PrintResultsFormatted(langStats, total, 1, 0, 0)
 		})
-		if !strings.Contains(output, "Go") || !strings.Contains(output, "Total") {
+		if !strings.Contains(output, "Go") || !strings.Contains(output, "Total") || !strings.Contains(output, "Disabled") || !strings.Contains(output, "4,000") {
 			t.Errorf("Output missing expected content: %s", output)
 		}
 	})
 
 	t.Run("JSON format", func(t *testing.T) {
 		output := captureStdout(func() {
-			PrintJSON(langStats, total)
+			PrintJSON(&Report{LangStats: langStats, Total: total})
 		})
-		if !strings.Contains(output, "\"languages\"") || !strings.Contains(output, "\"Go\"") {
+		if !strings.Contains(output, "\"languages\"") || !strings.Contains(output, "\"Go\"") || !strings.Contains(output, "\"mixed\": 5") || !strings.Contains(output, "\"doc\": 8") || !strings.Contains(output, "\"disabled\": 3") || !strings.Contains(output, "\"complexity\": 12") || !strings.Contains(output, "\"functions\": 4") || !strings.Contains(output, "\"avg_function_length\": 7.5") || !strings.Contains(output, "\"bytes\": 4096, \"characters\": 4000") || !strings.Contains(output, "\"uloc\": 60") || !strings.Contains(output, "\"code\": 70, \"lloc\": 45") {
 			t.Errorf("Output missing expected content: %s", output)
 		}
 	})
 
 	t.Run("Compact format", func(t *testing.T) {
 		output := captureStdout(func() {
-			PrintCompact(total)
+			PrintCompact(&Report{Total: total})
 		})
-		if !strings.Contains(output, "Code: 70") {
+		if !strings.Contains(output, "Code: 70") || !strings.Contains(output, "Mixed: 5") || !strings.Contains(output, "Doc: 8") || !strings.Contains(output, "Disabled: 3") || !strings.Contains(output, "Complexity: 12") || !strings.Contains(output, "Functions: 4 | Types: 2") || !strings.Contains(output, "Bytes: 4096 | Characters: 4000") || !strings.Contains(output, "ULOC: 60") || !strings.Contains(output, "Code: 70 | LLOC: 45") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("Report with optional sections", func(t *testing.T) {
+		report := &Report{
+			LangStats:      langStats,
+			Total:          total,
+			ProcessedFiles: 1,
+			Estimate:       &Estimate{ProjectType: "organic", Effort: 1.5},
+			Annotations:    []Annotation{{Path: "main.go", Line: 3, Marker: "TODO", Text: "TODO: tidy"}},
+		}
+		output := captureStdout(func() {
+			PrintReport(report, true)
+		})
+		if !strings.Contains(output, "Files processed: 1") || !strings.Contains(output, "Estimated cost to develop (COCOMO, organic)") || !strings.Contains(output, "TODO: tidy") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	groups := []FileGroup{{Name: "Generated", LangStats: langStats, Total: total}}
+
+	t.Run("Excluded groups", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintGroups(groups, false)
+		})
+		if !strings.Contains(output, "Generated files (excluded from totals)") || !strings.Contains(output, "Go") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("JSON format with excluded groups", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintJSON(&Report{LangStats: langStats, Total: total, Groups: groups})
+		})
+		if !strings.Contains(output, "\"excluded\"") || !strings.Contains(output, "\"generated\"") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("Compact format with excluded groups", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintCompact(&Report{Total: total, Groups: groups})
+		})
+		if !strings.Contains(output, "Generated (excluded): Files: 1 | Code: 70") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("Declarations", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintDeclarations(langStats, total, false)
+		})
+		if !strings.Contains(output, "Declarations:") || !strings.Contains(output, "Avg Fn Lines") || !strings.Contains(output, "7.5") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("Declarations without any", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintDeclarations(map[string]*LanguageStats{}, &LanguageStats{}, false)
+		})
+		if output != "" {
+			t.Errorf("Expected no output, got: %s", output)
+		}
+	})
+
+	complexFiles := []*FileStats{{FilePath: "src/main.go", Language: "Go", CodeLines: 40, Complexity: 9}}
+
+	t.Run("Most complex files", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintComplexFiles(complexFiles, false)
+		})
+		if !strings.Contains(output, "Most complex files") || !strings.Contains(output, "9  src/main.go (Go)") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("JSON format with complex files", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintJSON(&Report{LangStats: langStats, Total: total, ComplexFiles: complexFiles})
+		})
+		if !strings.Contains(output, `"complex_files"`) || !strings.Contains(output, `{"path": "src/main.go", "language": "Go", "complexity": 9, "code": 40}`) {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("Compact format with complex files", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintCompact(&Report{Total: total, ComplexFiles: complexFiles})
+		})
+		if !strings.Contains(output, "Complex: src/main.go | Complexity: 9 | Code: 40") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	breakdown := []LanguageShare{{Language: "Go", Bytes: 3000, Percent: 75}, {Language: "Shell", Bytes: 1000, Percent: 25}}
+
+	t.Run("Language breakdown", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintBreakdown(breakdown, true)
+		})
+		if !strings.Contains(output, "Language breakdown") || !strings.Contains(output, "3,000") || !strings.Contains(output, "75.0%") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("JSON format with breakdown", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintJSON(&Report{LangStats: langStats, Total: total, Breakdown: breakdown})
+		})
+		if !strings.Contains(output, `{"language": "Shell", "bytes": 1000, "percent": 25.0}`) {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("Compact format with breakdown", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintCompact(&Report{Total: total, Breakdown: breakdown})
+		})
+		if !strings.Contains(output, "Breakdown: Go 75.0% | Shell 25.0%") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	estimate := &Estimate{ProjectType: "organic", Effort: 26.9284, Schedule: 8.7382, People: 3.0817, Cost: 303138.87}
+
+	t.Run("Cost estimate", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintEstimate(estimate, true)
+		})
+		if !strings.Contains(output, "COCOMO, organic") || !strings.Contains(output, "26.93 person-months") || !strings.Contains(output, "303,139") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("JSON format with cost estimate", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintJSON(&Report{LangStats: langStats, Total: total, Estimate: estimate})
+		})
+		if !strings.Contains(output, `"cocomo": {"project_type": "organic", "effort": 26.93, "schedule": 8.74, "people": 3.08, "cost": 303138.87}`) {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("Compact format with cost estimate", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintCompact(&Report{Total: total, Estimate: estimate})
+		})
+		if !strings.Contains(output, "COCOMO (organic): Effort: 26.93 person-months | Schedule: 8.74 months | People: 3.08 | Cost: 303139") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	duplicates := []DuplicateGroup{{Files: []*FileStats{
+		{FilePath: "a/util.go", CodeLines: 12},
+		{FilePath: "b/util.go", CodeLines: 12},
+		{FilePath: "c/util.go", CodeLines: 12},
+	}}}
+
+	t.Run("Duplicate files", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintDuplicates(duplicates)
+		})
+		if !strings.Contains(output, "3 copies, 12 code lines each") || !strings.Contains(output, "  c/util.go") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("JSON format with duplicates", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintJSON(&Report{LangStats: langStats, Total: total, Duplicates: duplicates})
+		})
+		if !strings.Contains(output, `{"code": 12, "files": ["a/util.go", "b/util.go", "c/util.go"]}`) {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("Compact format with duplicates", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintCompact(&Report{Total: total, Duplicates: duplicates})
+		})
+		if !strings.Contains(output, "Duplicates: 1 groups | 2 copies") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	annotated := &LanguageStats{Language: "Total", Annotations: map[string]int{"TODO": 1200, "FIXME": 2}}
+	annotations := []Annotation{{Path: "src/main.go", Line: 12, Marker: "TODO", Text: "TODO: \"quoted\""}}
+
+	t.Run("Annotations", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintAnnotations(map[string]*LanguageStats{"Go": {Language: "Go", Annotations: annotated.Annotations}}, annotated, true)
+			PrintAnnotationList(annotations)
+		})
+		if !strings.Contains(output, "FIXME       TODO") || !strings.Contains(output, "1,200") || !strings.Contains(output, "src/main.go:12: TODO: \"quoted\"") {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("JSON format with annotations", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintJSON(&Report{LangStats: langStats, Total: annotated, Annotations: annotations})
+		})
+		if !strings.Contains(output, `"annotations": {"FIXME": 2, "TODO": 1200}}`) || !strings.Contains(output, `{"path": "src/main.go", "line": 12, "marker": "TODO", "text": "TODO: \"quoted\""}`) {
+			t.Errorf("Output missing expected content: %s", output)
+		}
+	})
+
+	t.Run("Compact format with annotations", func(t *testing.T) {
+		output := captureStdout(func() {
+			PrintCompact(&Report{Total: annotated, Annotations: annotations})
+		})
+		if !strings.Contains(output, "Annotations: FIXME 2 | TODO 1200") || !strings.Contains(output, "Annotation: src/main.go:12: TODO") {
 			t.Errorf("Output missing expected content: %s", output)
 		}
 	})
@@ -74,7 +310,7 @@ func TestPrintResults(t *testing.T) {
 
 func TestPrintErrors(t *testing.T) {
 	errs := []error{errors.New("error 1"), errors.New("error 2")}
-	output := captureStdout(func() {
+	output := captureStderr(func() {
 		PrintErrors(errs)
 	})
 	if !strings.Contains(output, "error 1") || !strings.Contains(output, "error 2") {
@@ -86,7 +322,7 @@ func TestPrintErrors(t *testing.T) {
 	for i := 0; i < 15; i++ {
 		manyErrs[i] = errors.New("error")
 	}
-	output = captureStdout(func() {
+	output = captureStderr(func() {
 		PrintErrors(manyErrs)
 	})
 	if !strings.Contains(output, "and 5 more errors") {
@@ -94,6 +330,58 @@ func TestPrintErrors(t *testing.T) {
 	}
 }
 
+func TestPrintDiagnostics(t *testing.T) {
+	fileStats := []*FileStats{
+		{FilePath: "clean.go"},
+		nil,
+		{FilePath: "stray.pl", Diagnostics: []Diagnostic{{Line: 12, Message: "unterminated string literal"}}},
+	}
+	output := captureStderr(func() {
+		PrintDiagnostics(fileStats)
+	})
+	if !strings.Contains(output, "stray.pl:12: unterminated string literal") {
+		t.Errorf("Output missing expected warning: %s", output)
+	}
+	if strings.Contains(output, "clean.go") {
+		t.Errorf("Output lists a file without diagnostics: %s", output)
+	}
+
+	output = captureStderr(func() {
+		PrintDiagnostics(fileStats[:1])
+	})
+	if output != "" {
+		t.Errorf("Expected no output without diagnostics, got: %s", output)
+	}
+}
+
+func TestPrintSkipped(t *testing.T) {
+	skipped := []SkippedFile{
+		{Path: "a.bin", Reason: SkipBinaryExtension},
+		{Path: "data", Reason: SkipBinaryNUL},
+		{Path: "b.png", Reason: SkipBinaryExtension},
+	}
+
+	var output string
+	stdout := captureStdout(func() {
+		output = captureStderr(func() {
+			PrintSkipped(skipped)
+		})
+	})
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got %q", stdout)
+	}
+	if !strings.Contains(output, "binary extension: 2") || !strings.Contains(output, "binary content (NUL bytes): 1") {
+		t.Errorf("Output missing expected content: %s", output)
+	}
+
+	output = captureStderr(func() {
+		PrintSkipped(nil)
+	})
+	if output != "" {
+		t.Errorf("Expected no output, got %q", output)
+	}
+}
+
 func TestFormatNumber(t *testing.T) {
 	tests := []struct {
 		n    int
@@ -0,0 +1,115 @@
+package main
+
+import "strings"
+
+// conditional is an open #if group
+type conditional struct {
+	// dead is set while the current branch can never be compiled
+	dead bool
+
+	// taken is set once a branch that is always compiled has been seen, so
+	// that every later #elif or #else is dead
+	taken bool
+}
+
+// trackConditional follows the #if, #elif, #else and #endif directives of a
+// line and reports whether the line lies in code the preprocessor removes.
+// A directive opening or closing a disabled branch is itself live code.
+func (c *lineCounter) trackConditional(line string) bool {
+	keyword, arg, ok := directive(line)
+	if !ok {
+		return c.inDeadBranch(len(c.conditionals))
+	}
+
+	depth := len(c.conditionals)
+	switch keyword {
+	case "if", "ifdef", "ifndef":
+		cond := conditional{}
+		if keyword == "if" {
+			value, known := constantCondition(arg)
+			cond.dead = known && !value
+			cond.taken = known && value
+		}
+		c.conditionals = append(c.conditionals, cond)
+		return c.inDeadBranch(depth)
+	case "elif", "elifdef", "elifndef":
+		if depth == 0 {
+			return false
+		}
+		cond := &c.conditionals[depth-1]
+		value, known := constantCondition(arg)
+		if keyword != "elif" {
+			known = false
+		}
+		cond.dead = cond.taken || known && !value
+		cond.taken = cond.taken || known && value
+		return c.inDeadBranch(depth - 1)
+	case "else":
+		if depth == 0 {
+			return false
+		}
+		cond := &c.conditionals[depth-1]
+		cond.dead = cond.taken
+		return c.inDeadBranch(depth - 1)
+	case "endif":
+		if depth == 0 {
+			return false
+		}
+		c.conditionals = c.conditionals[:depth-1]
+		return c.inDeadBranch(depth - 1)
+	}
+	return c.inDeadBranch(depth)
+}
+
+// inDeadBranch reports whether any of the outermost depth open groups is in
+// a dead branch
+func (c *lineCounter) inDeadBranch(depth int) bool {
+	for _, cond := range c.conditionals[:depth] {
+		if cond.dead {
+			return true
+		}
+	}
+	return false
+}
+
+// directive splits a preprocessor directive such as "#  if 0 // old" into
+// its keyword and argument, without any trailing comment
+func directive(line string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), "#")
+	if !ok {
+		return "", "", false
+	}
+	rest = strings.TrimLeft(rest, " \t")
+
+	end := 0
+	for end < len(rest) && isIdentChar(rest[end]) {
+		end++
+	}
+	if end == 0 {
+		return "", "", false
+	}
+
+	arg := rest[end:]
+	if i := strings.Index(arg, "//"); i >= 0 {
+		arg = arg[:i]
+	}
+	if i := strings.Index(arg, "/*"); i >= 0 {
+		arg = arg[:i]
+	}
+	return rest[:end], strings.TrimSpace(arg), true
+}
+
+// constantCondition evaluates an #if condition that is the literal 0 or 1,
+// possibly in parentheses. Any other condition depends on the build.
+func constantCondition(arg string) (value, known bool) {
+	for len(arg) >= 2 && arg[0] == '(' && arg[len(arg)-1] == ')' {
+		arg = strings.TrimSpace(arg[1 : len(arg)-1])
+	}
+	switch arg {
+	case "0":
+		return false, true
+	case "1":
+		return true, true
+	}
+	return false, false
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCountLinesDisabledCode(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name         string
+		filename     string
+		content      string
+		disabledCode bool
+		wantBlank    int
+		wantComment  int
+		wantDisabled int
+		wantCode     int
+	}{
+		{
+			name:     "Off by default",
+			filename: "legacy.c",
+			content:  "#if 0\nint old(void);\n#endif\n",
+			wantCode: 3,
+		},
+		{
+			name:         "If 0 block",
+			filename:     "legacy.c",
+			content:      "int x;\n#if 0 /* legacy */\nint old(void);\n\nit's prose\n#endif\nint y;\n",
+			disabledCode: true,
+			wantBlank:    1,
+			wantComment:  2,
+			wantDisabled: 2,
+			wantCode:     4,
+		},
+		{
+			name:         "Else branch of if 1",
+			filename:     "feature.cpp",
+			content:      "#if (1)\nnew_path();\n#else\nold_path();\n#endif\n",
+			disabledCode: true,
+			wantComment:  1,
+			wantDisabled: 1,
+			wantCode:     4,
+		},
+		{
+			name:         "Else branch of if 0 is live",
+			filename:     "feature.h",
+			content:      "#if 0\nold_path();\n#elif defined(FAST)\nfast_path();\n#else\nnew_path();\n#endif\n",
+			disabledCode: true,
+			wantComment:  1,
+			wantDisabled: 1,
+			wantCode:     6,
+		},
+		{
+			name:         "Nested conditionals",
+			filename:     "nested.c",
+			content:      "#if 0\n#ifdef DEBUG\nlog();\n#endif\n#else\nrun();\n#endif\n",
+			disabledCode: true,
+			wantComment:  3,
+			wantDisabled: 3,
+			wantCode:     4,
+		},
+		{
+			name:         "Directive inside a block comment",
+			filename:     "comment.c",
+			content:      "/*\n#if 0\n*/\nint x;\n",
+			disabledCode: true,
+			wantComment:  3,
+			wantCode:     1,
+		},
+		{
+			name:         "Languages without a preprocessor",
+			filename:     "script.py",
+			content:      "#if 0\nx = 1\n",
+			disabledCode: true,
+			wantComment:  1,
+			wantCode:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			opts := CountOptions{DisabledCode: tt.disabledCode}
+			stats, err := CountLinesWithOptions(filePath, Languages[filepath.Ext(tt.filename)], opts)
+			if err != nil {
+				t.Fatalf("CountLinesWithOptions failed: %v", err)
+			}
+
+			if stats.BlankLines != tt.wantBlank {
+				t.Errorf("BlankLines = %d, want %d", stats.BlankLines, tt.wantBlank)
+			}
+			if stats.CommentLines != tt.wantComment {
+				t.Errorf("CommentLines = %d, want %d", stats.CommentLines, tt.wantComment)
+			}
+			if stats.DisabledLines != tt.wantDisabled {
+				t.Errorf("DisabledLines = %d, want %d", stats.DisabledLines, tt.wantDisabled)
+			}
+			if stats.CodeLines != tt.wantCode {
+				t.Errorf("CodeLines = %d, want %d", stats.CodeLines, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestDirective(t *testing.T) {
+	tests := []struct {
+		line        string
+		wantKeyword string
+		wantArg     string
+		wantOK      bool
+	}{
+		{"#if 0", "if", "0", true},
+		{"  #  if 0 // old", "if", "0", true},
+		{"#endif /* FOO */", "endif", "", true},
+		{"#else", "else", "", true},
+		{"int x; #if", "", "", false},
+		{"#", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			keyword, arg, ok := directive(tt.line)
+			if keyword != tt.wantKeyword || arg != tt.wantArg || ok != tt.wantOK {
+				t.Errorf("directive(%q) = %q, %q, %v, want %q, %q, %v", tt.line, keyword, arg, ok, tt.wantKeyword, tt.wantArg, tt.wantOK)
+			}
+		})
+	}
+}
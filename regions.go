@@ -0,0 +1,299 @@
+package main
+
+import (
+	"strings"
+)
+
+// scriptTypes maps the type attribute of an embedded element to the
+// extension of the language it holds
+var scriptTypes = map[string]string{
+	"module":                 ".js",
+	"text/javascript":        ".js",
+	"application/javascript": ".js",
+	"text/typescript":        ".ts",
+	"application/json":       ".json",
+	"application/ld+json":    ".json",
+	"importmap":              ".json",
+	"text/css":               ".css",
+}
+
+// regionCounter counts container languages such as Vue, Svelte and HTML,
+// handing the content of embedded elements to a lineCounter for the language
+// they are written in. Fenced code blocks in Markdown are handled the same
+// way. Lines holding the opening and closing tags or fences belong to the
+// container.
+type regionCounter struct {
+	lang      *Language
+	stats     *FileStats
+	lines     *lineTracker
+	container *lineCounter
+	embedded  map[string]*FileStats
+	fences    []string
+
+	active   *lineCounter
+	closeTag string
+
+	// The counter the current line was routed to
+	target *lineCounter
+
+	// The opening fence of the current code block, whose content stays in
+	// the container when active is nil
+	fence string
+
+	// An opening tag whose attributes continue on the following lines
+	pending    *EmbeddedRegion
+	pendingTag string
+}
+
+// newRegionCounter creates a regionCounter that records container lines into
+// stats and embedded lines into stats.Embedded
+func newRegionCounter(lang *Language, stats *FileStats, lines *lineTracker, opts CountOptions) *regionCounter {
+	r := &regionCounter{
+		lang:      lang,
+		stats:     stats,
+		lines:     lines,
+		container: newLineCounter(lang, stats, lines),
+		embedded:  make(map[string]*FileStats),
+	}
+	if opts.MarkdownCode {
+		r.fences = lang.CodeFences
+	}
+	return r
+}
+
+// beginLine routes a line to the active region or to the container. Tags
+// and fences are recognised within the head of the line.
+func (r *regionCounter) beginLine(line string) {
+	r.target = r.route(line)
+	r.target.beginLine(line)
+}
+
+// scan passes text of the current line to the counter it was routed to
+func (r *regionCounter) scan(text string, start int, final bool) int {
+	return r.target.scan(text, start, final)
+}
+
+// endLine classifies the current line in the counter it was routed to
+func (r *regionCounter) endLine() {
+	r.target.endLine()
+}
+
+// route returns the counter for a line, opening or closing regions as its
+// tags and fences require
+func (r *regionCounter) route(line string) *lineCounter {
+	if r.fence != "" {
+		if isClosingFence(line, r.fence) {
+			if r.active != nil {
+				r.active.finish()
+			}
+			r.fence = ""
+			r.active = nil
+			return r.container
+		}
+		if r.active != nil {
+			return r.active
+		}
+		return r.container
+	}
+
+	if r.active != nil {
+		if strings.Contains(strings.ToLower(line), r.closeTag) {
+			r.active.finish()
+			r.active = nil
+			return r.container
+		}
+		return r.active
+	}
+
+	if r.pending != nil {
+		end := strings.IndexByte(line, '>')
+		if end < 0 {
+			r.pendingTag += " " + line
+			return r.container
+		}
+		region := r.pending
+		r.pending = nil
+		r.open(region, r.pendingTag+" "+line[:end], line[end+1:])
+		return r.container
+	}
+
+	if r.container.inMultiLine {
+		return r.container
+	}
+
+	if fence, info, ok := openingFence(line, r.fences); ok {
+		r.fence = fence
+		if lang := GetLanguageByAlias(info); lang != nil {
+			r.active = newLineCounter(lang, r.embeddedStats(lang), r.lines)
+		}
+		return r.container
+	}
+
+	trimmed := strings.TrimSpace(line)
+	for i := range r.lang.EmbeddedRegions {
+		region := &r.lang.EmbeddedRegions[i]
+		if !isOpenTag(trimmed, region.Tag) {
+			continue
+		}
+		end := strings.IndexByte(trimmed, '>')
+		if end < 0 {
+			r.pending = region
+			r.pendingTag = trimmed
+			return r.container
+		}
+		r.open(region, trimmed[:end], trimmed[end+1:])
+		return r.container
+	}
+	return r.container
+}
+
+// finish reports state left open in the container and the active region
+func (r *regionCounter) finish() {
+	r.container.finish()
+	if r.active != nil {
+		r.active.finish()
+	}
+}
+
+// open starts counting the content of a region on the following line, unless
+// the element is self-closing or closed on the line it opened
+func (r *regionCounter) open(region *EmbeddedRegion, tag, rest string) {
+	closeTag := "</" + strings.ToLower(region.Tag)
+	if strings.HasSuffix(strings.TrimSpace(tag), "/") || strings.Contains(strings.ToLower(rest), closeTag) {
+		return
+	}
+
+	lang := r.regionLanguage(region, tag)
+	if lang == nil {
+		return
+	}
+
+	// Each element starts with fresh string and comment state
+	r.active = newLineCounter(lang, r.embeddedStats(lang), r.lines)
+	r.closeTag = closeTag
+}
+
+// embeddedStats returns the statistics collecting the regions of a language
+func (r *regionCounter) embeddedStats(lang *Language) *FileStats {
+	child, ok := r.embedded[lang.Name]
+	if !ok {
+		child = &FileStats{
+			FilePath: r.stats.FilePath,
+			Language: lang.Name,
+		}
+		r.embedded[lang.Name] = child
+		r.stats.Embedded = append(r.stats.Embedded, child)
+	}
+	return child
+}
+
+// regionLanguage resolves the language of an element from its lang or type
+// attribute, falling back to the region's default. It returns nil when the
+// content stays in the container.
+func (r *regionCounter) regionLanguage(region *EmbeddedRegion, tag string) *Language {
+	if alias := tagAttribute(tag, "lang"); alias != "" {
+		if lang := GetLanguageByAlias(alias); lang != nil {
+			return lang
+		}
+	}
+
+	ext := region.Language
+	if typ := tagAttribute(tag, "type"); typ != "" {
+		ext = scriptTypes[strings.ToLower(typ)]
+	}
+	if ext == "" {
+		return nil
+	}
+	return GetLanguage(ext)
+}
+
+// isOpenTag reports whether s starts with an opening tag for the element
+func isOpenTag(s, tag string) bool {
+	if len(s) < len(tag)+1 || s[0] != '<' || !strings.EqualFold(s[1:len(tag)+1], tag) {
+		return false
+	}
+	if len(s) == len(tag)+1 {
+		return true
+	}
+	next := s[len(tag)+1]
+	return next == '>' || next == '/' || isWhitespace(next)
+}
+
+// openingFence reports whether the line opens a fenced code block with one of
+// the given markers. It returns the full fence and the language named in the
+// info string.
+func openingFence(line string, markers []string) (fence, info string, ok bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return "", "", false
+	}
+	for _, marker := range markers {
+		if !strings.HasPrefix(trimmed, marker) {
+			continue
+		}
+		n := len(marker)
+		for n < len(trimmed) && trimmed[n] == marker[0] {
+			n++
+		}
+		rest := trimmed[n:]
+		if marker[0] == '`' && strings.IndexByte(rest, '`') >= 0 {
+			return "", "", false
+		}
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			info = strings.Trim(fields[0], "{}.")
+		}
+		return trimmed[:n], info, true
+	}
+	return "", "", false
+}
+
+// isClosingFence reports whether the line closes a code block opened by fence
+func isClosingFence(line, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	if len(line)-len(strings.TrimLeft(line, " ")) > 3 || len(trimmed) < len(fence) {
+		return false
+	}
+	for i := 0; i < len(trimmed); i++ {
+		if trimmed[i] != fence[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// tagAttribute returns the value of the named attribute in an opening tag
+func tagAttribute(tag, name string) string {
+	lower := strings.ToLower(tag)
+	for from := 0; ; {
+		idx := strings.Index(lower[from:], name)
+		if idx < 0 {
+			return ""
+		}
+		idx += from
+		from = idx + len(name)
+
+		// The name must stand alone and be followed by '='
+		if idx > 0 && !isWhitespace(lower[idx-1]) {
+			continue
+		}
+		rest := strings.TrimLeft(tag[from:], " \t")
+		if !strings.HasPrefix(rest, "=") {
+			continue
+		}
+		rest = strings.TrimLeft(rest[1:], " \t")
+		if rest == "" {
+			return ""
+		}
+		if quote := rest[0]; quote == '"' || quote == '\'' {
+			if end := strings.IndexByte(rest[1:], quote); end >= 0 {
+				return rest[1 : end+1]
+			}
+			return rest[1:]
+		}
+		end := strings.IndexAny(rest, " \t/>")
+		if end < 0 {
+			return rest
+		}
+		return rest[:end]
+	}
+}
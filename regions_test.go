@@ -0,0 +1,209 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCountLinesEmbeddedRegions(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	type counts struct {
+		blank, comment, code, total int
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		lang     *Language
+		want     counts
+		embedded map[string]counts
+	}{
+		{
+			name:     "Vue single-file component",
+			filename: "App.vue",
+			content: `<template>
+  <!-- greeting -->
+  <div>{{ msg }}</div>
+</template>
+
+<script setup
+        lang="ts">
+// component state
+const msg: string = "hi" // inline
+</script>
+
+<style lang="scss" scoped>
+/* theme */
+.a { color: red; }
+</style>
+`,
+			lang: Languages[".vue"],
+			want: counts{blank: 2, comment: 1, code: 8, total: 11},
+			embedded: map[string]counts{
+				"TypeScript": {comment: 1, code: 1, total: 2},
+				"SCSS":       {comment: 1, code: 1, total: 2},
+			},
+		},
+		{
+			name:     "HTML with inline script and JSON data",
+			filename: "index.html",
+			content: `<html>
+<script>
+  // setup
+  init();
+</script>
+<script type="application/ld+json">
+  {"@type": "Thing"}
+</script>
+<script src="app.js"></script>
+<style>
+  body { margin: 0; }
+</style>
+</html>
+`,
+			lang: Languages[".html"],
+			want: counts{code: 9, total: 9},
+			embedded: map[string]counts{
+				"JavaScript": {comment: 1, code: 1, total: 2},
+				"JSON":       {code: 1, total: 1},
+				"CSS":        {code: 1, total: 1},
+			},
+		},
+		{
+			name:     "Commented out script stays in container",
+			filename: "page.svelte",
+			content: `<!--
+<script>
+  let x = 1;
+</script>
+-->
+<h1>Hello</h1>
+`,
+			lang:     Languages[".svelte"],
+			want:     counts{comment: 5, code: 1, total: 6},
+			embedded: map[string]counts{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, tt.lang)
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			got := counts{stats.BlankLines, stats.CommentLines, stats.CodeLines, stats.TotalLines}
+			if got != tt.want {
+				t.Errorf("container counts = %+v, want %+v", got, tt.want)
+			}
+
+			if len(stats.Embedded) != len(tt.embedded) {
+				t.Fatalf("got %d embedded languages, want %d", len(stats.Embedded), len(tt.embedded))
+			}
+			for _, child := range stats.Embedded {
+				want, ok := tt.embedded[child.Language]
+				if !ok {
+					t.Errorf("unexpected embedded language %q", child.Language)
+					continue
+				}
+				got := counts{child.BlankLines, child.CommentLines, child.CodeLines, child.TotalLines}
+				if got != want {
+					t.Errorf("%s counts = %+v, want %+v", child.Language, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCountLinesMarkdownCode(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	content := "# Guide\n" +
+		"\n" +
+		"```go\n" +
+		"// main is the entry point\n" +
+		"func main() {}\n" +
+		"```\n" +
+		"\n" +
+		"~~~~ python title=\"example\"\n" +
+		"print('hi')\n" +
+		"~~~\n" +
+		"~~~~\n" +
+		"```\n" +
+		"plain output\n" +
+		"```go\n" +
+		"```\n"
+	filePath := filepath.Join(tmpDir, "guide.md")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	stats, err := CountLinesWithOptions(filePath, Languages[".md"], CountOptions{MarkdownCode: true})
+	if err != nil {
+		t.Fatalf("CountLinesWithOptions failed: %v", err)
+	}
+
+	// Fence lines, prose and the untagged block stay in Markdown
+	if stats.CodeLines != 9 || stats.BlankLines != 2 || stats.TotalLines != 11 {
+		t.Errorf("Markdown counts = code %d, blank %d, total %d; want 9, 2, 11",
+			stats.CodeLines, stats.BlankLines, stats.TotalLines)
+	}
+
+	want := map[string][2]int{"Go": {1, 1}, "Python": {0, 2}}
+	if len(stats.Embedded) != len(want) {
+		t.Fatalf("got %d embedded languages, want %d", len(stats.Embedded), len(want))
+	}
+	for _, child := range stats.Embedded {
+		w := want[child.Language]
+		if child.CommentLines != w[0] || child.CodeLines != w[1] {
+			t.Errorf("%s: comment %d, code %d; want %d, %d",
+				child.Language, child.CommentLines, child.CodeLines, w[0], w[1])
+		}
+	}
+
+	// Without the option Markdown is counted as a single language
+	stats, err = CountLines(filePath, Languages[".md"])
+	if err != nil {
+		t.Fatalf("CountLines failed: %v", err)
+	}
+	if len(stats.Embedded) != 0 || stats.TotalLines != 15 {
+		t.Errorf("got %d embedded languages and %d lines, want 0 and 15", len(stats.Embedded), stats.TotalLines)
+	}
+}
+
+func TestTagAttribute(t *testing.T) {
+	tests := []struct {
+		tag  string
+		name string
+		want string
+	}{
+		{`<script lang="ts"`, "lang", "ts"},
+		{`<style scoped lang='scss'`, "lang", "scss"},
+		{`<script lang=tsx setup`, "lang", "tsx"},
+		{`<script type = "module"`, "type", "module"},
+		{`<script data-lang="ts"`, "lang", ""},
+		{`<script setup`, "lang", ""},
+	}
+
+	for _, tt := range tests {
+		if got := tagAttribute(tt.tag, tt.name); got != tt.want {
+			t.Errorf("tagAttribute(%q, %q) = %q, want %q", tt.tag, tt.name, got, tt.want)
+		}
+	}
+}
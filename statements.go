@@ -0,0 +1,134 @@
+package main
+
+import "strings"
+
+// Statements describes how the logical source lines of a language, its
+// statements, are counted in the code outside comments and strings. Each
+// terminator ends a statement and each block opener starts one, except
+// within brackets, so "for (i = 0; i < n; i++) {" is a single statement.
+// In languages where a newline may end a statement, a code line ends one
+// unless an open bracket, an unfinished string or one of Continuers carries
+// it on to the next line, or it already ended with a terminator or block.
+type Statements struct {
+	Terminators string // e.g. ";"
+	Blocks      string // opening and closing characters, e.g. "{}"
+	Brackets    string // opening and closing pairs, e.g. "()[]"
+
+	// Literals are the code characters after which, like after "return",
+	// an opening block character starts a literal such as an initializer
+	// or object, e.g. "=(,:[" for "= {1, 2}" and "f({a: 1})"
+	Literals string
+
+	Newline    bool
+	Continuers string // line endings that continue a statement, e.g. "\\,"
+
+	// Enders, if set, are the only line endings besides words and numbers
+	// after which a newline ends a statement, following Go's semicolon
+	// insertion rule
+	Enders string
+}
+
+var (
+	// Statements end at a semicolon
+	cStatements = &Statements{Terminators: ";", Blocks: "{}", Brackets: "()[]", Literals: "=(,:[]"}
+
+	// Statements end at a semicolon, in languages whose blocks are words
+	// such as BEGIN and END
+	sqlStatements = &Statements{Terminators: ";", Brackets: "()[]"}
+
+	// Dereferences such as $h->{a} and @{$list} are literals too
+	plStatements = &Statements{Terminators: ";", Blocks: "{}", Brackets: "()[]", Literals: "=(,:[>$@%"}
+
+	// A semicolon or a newline ends a statement, unless the line ends inside
+	// brackets or with an operator
+	jsStatements = &Statements{Terminators: ";", Blocks: "{}", Brackets: "()[]", Literals: "=(,:[", Newline: true, Continuers: `\,.=+-*/%&|^<>!?:`}
+
+	goStatements  = &Statements{Newline: true, Enders: ")]}\"'`"}
+	pyStatements  = &Statements{Terminators: ";", Brackets: "()[]{}", Newline: true, Continuers: `\`}
+	rbStatements  = &Statements{Terminators: ";", Brackets: "()[]{}", Newline: true, Continuers: `\,`}
+	shStatements  = &Statements{Terminators: ";", Newline: true, Continuers: `\|&`}
+	luaStatements = &Statements{Terminators: ";", Brackets: "()[]{}", Newline: true, Continuers: ","}
+)
+
+// trackStatement counts the statement a code character ends or opens, and
+// the brackets and blocks it is nested in
+func (c *lineCounter) trackStatement(ch byte) {
+	st := c.lang.Statements
+	if st == nil {
+		return
+	}
+	prev, afterReturn := c.statementPrev, string(c.statementWord) == "return"
+	c.statementPrev = ch
+	if isIdentChar(ch) {
+		if len(c.statementWord) <= len("return") {
+			c.statementWord = append(c.statementWord, ch)
+		}
+	} else {
+		c.statementWord = c.statementWord[:0]
+	}
+
+	switch {
+	case len(st.Blocks) == 2 && ch == st.Blocks[0] && st.Literals != "" && (strings.IndexByte(st.Literals, prev) >= 0 || afterReturn):
+		// A literal is bracketed like a list; -1 marks it on the stack
+		c.blockDepths = append(c.blockDepths, -1)
+		c.statementDepth++
+	case len(st.Blocks) == 2 && ch == st.Blocks[0]:
+		if c.statementDepth == 0 {
+			c.stats.LogicalLines++
+		}
+		// Statements inside a block, even one passed within brackets like
+		// a JavaScript callback, are counted
+		c.blockDepths = append(c.blockDepths, c.statementDepth)
+		c.statementDepth = 0
+	case len(st.Blocks) == 2 && ch == st.Blocks[1]:
+		depth := 0
+		if n := len(c.blockDepths); n > 0 {
+			depth = c.blockDepths[n-1]
+			c.blockDepths = c.blockDepths[:n-1]
+		}
+		c.literalClosed = depth < 0
+		if c.literalClosed {
+			depth = max(c.statementDepth-1, 0)
+		}
+		c.statementDepth = depth
+	case strings.IndexByte(st.Terminators, ch) >= 0:
+		if c.statementDepth == 0 {
+			c.stats.LogicalLines++
+		}
+	default:
+		if i := strings.IndexByte(st.Brackets, ch); i >= 0 {
+			if i%2 == 0 {
+				c.statementDepth++
+			} else if c.statementDepth > 0 {
+				c.statementDepth--
+			}
+		}
+	}
+}
+
+// trackStatementEnd counts the statement ended by the newline of the current
+// code line, in languages where one can
+func (c *lineCounter) trackStatementEnd() {
+	st := c.lang.Statements
+	if st == nil || !st.Newline || c.inHeredoc || c.inString || c.statementDepth > 0 {
+		return
+	}
+
+	last := c.lastCodeChar
+	if (last == '+' || last == '-') && c.prevCodeChar == last {
+		// An increment or decrement, not an operator awaiting its operand
+		c.stats.LogicalLines++
+		return
+	}
+	switch {
+	case strings.IndexByte(st.Terminators, last) >= 0:
+		return // already counted
+	case strings.IndexByte(st.Blocks, last) >= 0 && !(last == st.Blocks[1] && c.literalClosed):
+		return // the start or end of a block
+	case strings.IndexByte(st.Continuers, last) >= 0:
+		return
+	case st.Enders != "" && !isIdentChar(last) && strings.IndexByte(st.Enders, last) < 0:
+		return
+	}
+	c.stats.LogicalLines++
+}
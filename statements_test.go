@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCountLinesLogicalLines(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name        string
+		filename    string
+		content     string
+		wantCode    int
+		wantLogical int
+	}{
+		{
+			name:     "Go semicolon insertion",
+			filename: "main.go",
+			content: `package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	for i := 0; i < 3; i++ {
+		fmt.Println(i,
+			"x")
+	}
+	x := 1 +
+		2
+	x++ // done
+}
+`,
+			wantCode:    13,
+			wantLogical: 8,
+		},
+		{
+			name:        "Python continuation",
+			filename:    "app.py",
+			content:     "def f(a,\n      b):\n    x = {\n        \"a\": 1,\n    }\n    return x;\n\ns = \"\"\"\ntext\n\"\"\"\ny = 1 + \\\n    2\n",
+			wantCode:    11,
+			wantLogical: 5,
+		},
+		{
+			name:        "C terminators and blocks",
+			filename:    "loop.c",
+			content:     "for (int i = 0; i < n; i++) {\n  foo(i); /* a; b; */\n}\nchar *s = \"a;b\";\n",
+			wantCode:    4,
+			wantLogical: 3,
+		},
+		{
+			name:        "C initializers",
+			filename:    "init.c",
+			content:     "int a[] = {1, 2, 3};\nstruct s x = { .a = 1 };\nint f(void) {\n  return x.a;\n}\n",
+			wantCode:    5,
+			wantLogical: 4,
+		},
+		{
+			name:        "JavaScript with semicolons",
+			filename:    "semi.js",
+			content:     "const a = 1;\nfoo(() => {\n  bar();\n});\nlet c = a +\n  b;\ni++;\n",
+			wantCode:    7,
+			wantLogical: 5,
+		},
+		{
+			name:        "JavaScript without semicolons",
+			filename:    "bare.js",
+			content:     "const a = 1\nfoo(() => {\n  bar()\n})\nlet c = a +\n  b\ni++\n",
+			wantCode:    7,
+			wantLogical: 5,
+		},
+		{
+			name:        "JavaScript object literals",
+			filename:    "obj.js",
+			content:     "const o = {\n  a: 1,\n  b: { c: [1, 2] },\n}\nfunction f() {\n  return { a: 1 }\n}\nfoo({ a: 1 })\n",
+			wantCode:    8,
+			wantLogical: 4,
+		},
+		{
+			name:        "Perl hash references",
+			filename:    "hash.pl",
+			content:     "my $h = { a => 1, b => [1, 2] };\nprint $h->{a};\nmy @l = @{$r};\n",
+			wantCode:    3,
+			wantLogical: 3,
+		},
+		{
+			name:        "PHP here-documents",
+			filename:    "heredoc.php",
+			content:     "<?php\n$a = <<<EOT\n  text;\nEOT;\n$b = <<<'EOT'\n  more\n  EOT . \"x\"; // tail\n",
+			wantCode:    7,
+			wantLogical: 2,
+		},
+		{
+			name:        "Shell",
+			filename:    "run.sh",
+			content:     "cd /tmp; ls\nmake |\n  tee log\ncat <<EOF\n;;;\nEOF\n",
+			wantCode:    6,
+			wantLogical: 4,
+		},
+		{
+			name:        "SQL",
+			filename:    "schema.sql",
+			content:     "CREATE TABLE t (\n  id INT,\n  name TEXT\n);\nselect * from t where name = 'a;b';\n",
+			wantCode:    5,
+			wantLogical: 2,
+		},
+		{
+			name:     "Language without rules",
+			filename: "config.yaml",
+			content:  "a: 1\nb: 2\n",
+			wantCode: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, Languages[filepath.Ext(tt.filename)])
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			if stats.CodeLines != tt.wantCode {
+				t.Errorf("CodeLines = %d, want %d", stats.CodeLines, tt.wantCode)
+			}
+			if stats.LogicalLines != tt.wantLogical {
+				t.Errorf("LogicalLines = %d, want %d", stats.LogicalLines, tt.wantLogical)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// lineHeadSize is the size of the read buffer, and so the most of a line
+// that is seen at once. Checks that look at a line as a whole, such as
+// finding an embedded <script> tag, only see this much of it.
+const lineHeadSize = 64 * 1024
+
+// maxLookahead is the most a counter reads past its position to match a
+// comment marker or string delimiter. Text this close to the end of a
+// segment is carried over to the next one.
+const maxLookahead = 64
+
+// lineSink consumes the lines of a file as a stream, so that lines of any
+// length are counted in constant memory
+type lineSink interface {
+	// beginLine starts a new line, given up to lineHeadSize bytes from
+	// its start
+	beginLine(head string)
+
+	// scan classifies the text of the current line from offset start,
+	// where text[:start] has already been scanned. Unless final is set,
+	// it stops short of the end to keep maxLookahead bytes of context and
+	// returns the offset it reached.
+	scan(text string, start int, final bool) int
+
+	// endLine classifies the line once all of its text has been scanned
+	endLine()
+
+	// finish reports any state left open at the end of the file
+	finish()
+}
+
+// countLine feeds a complete line to a sink
+func countLine(sink lineSink, line string) {
+	sink.beginLine(line)
+	sink.scan(line, 0, true)
+	sink.endLine()
+}
+
+// textShape describes the physical layout of a text
+type textShape struct {
+	lineEndings string
+	bytes       int // excluding line terminators
+	characters  int // including line terminators
+	whitespace  int
+	longestLine int
+}
+
+// countStream splits r into lines and feeds them to sink in segments of at
+// most lineHeadSize bytes. It returns the layout of the text.
+func countStream(r io.Reader, sink lineSink, lines *lineTracker) (textShape, error) {
+	reader := &lineReader{r: bufio.NewReaderSize(r, lineHeadSize)}
+
+	// The unscanned end of the current line, plus one byte before it so
+	// that scanners can look back at the previous character
+	carry := ""
+	start := 0
+	inLine := false
+
+	var shape textShape
+	lineLength := 0
+
+	for {
+		segment, eol, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return textShape{}, err
+		}
+
+		text := string(segment)
+		if !inLine {
+			lines.line++
+			sink.beginLine(text)
+			inLine = true
+			lineLength = 0
+		}
+
+		shape.bytes += len(segment)
+		shape.characters += countCharacters(segment)
+		shape.whitespace += countWhitespace(segment)
+		lineLength += len(segment)
+		shape.longestLine = max(shape.longestLine, lineLength)
+
+		text = carry + text
+		stop := sink.scan(text, start, eol)
+		if eol {
+			sink.endLine()
+			carry, start, inLine = "", 0, false
+			continue
+		}
+		keep := max(stop-1, 0)
+		carry, start = text[keep:], stop-keep
+	}
+
+	if inLine {
+		sink.scan(carry, start, true)
+		sink.endLine()
+	}
+	sink.finish()
+
+	shape.lineEndings = reader.endings.style()
+	shape.characters += reader.terminators
+	return shape, nil
+}
+
+// countCharacters returns the number of UTF-8 characters starting in b, so
+// that a character split between segments is counted once
+func countCharacters(b []byte) int {
+	n := 0
+	for _, c := range b {
+		if c&0xC0 != 0x80 {
+			n++
+		}
+	}
+	return n
+}
+
+// countWhitespace returns the number of whitespace bytes in b
+func countWhitespace(b []byte) int {
+	n := 0
+	for _, c := range b {
+		if isWhitespace(c) {
+			n++
+		}
+	}
+	return n
+}
+
+// lineReader returns the lines of a text in segments, splitting at LF, CRLF
+// or a lone CR
+type lineReader struct {
+	r           *bufio.Reader
+	endings     lineEndings
+	terminators int // characters in the line terminators read
+}
+
+// next returns the next segment of the current line and whether it ends the
+// line. The segment is only valid until the following call.
+func (lr *lineReader) next() ([]byte, bool, error) {
+	data, err := lr.r.Peek(lr.r.Size())
+	if err != nil && err != io.EOF {
+		return nil, false, err
+	}
+	if len(data) == 0 {
+		return nil, false, io.EOF
+	}
+
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		switch {
+		case data[i] == '\n':
+			lr.endings.lf = true
+			lr.terminators++
+			lr.r.Discard(i + 1)
+		case i+1 < len(data) && data[i+1] == '\n':
+			lr.endings.crlf = true
+			lr.terminators += 2
+			lr.r.Discard(i + 2)
+		case i+1 < len(data) || err == io.EOF:
+			lr.endings.cr = true
+			lr.terminators++
+			lr.r.Discard(i + 1)
+		default:
+			// A CR at the end of a full buffer may start a CRLF, so
+			// return the text before it and decide on the next call
+			lr.r.Discard(i)
+			return data[:i], false, nil
+		}
+		return data[:i], true, nil
+	}
+
+	lr.r.Discard(len(data))
+	return data, err == io.EOF, nil
+}
+
+// lineEndings records the line terminators seen while splitting a file
+type lineEndings struct {
+	lf, crlf, cr bool
+}
+
+// style summarises the line terminators seen
+func (e *lineEndings) style() string {
+	switch {
+	case e.lf && !e.crlf && !e.cr:
+		return LineEndingsLF
+	case e.crlf && !e.lf && !e.cr:
+		return LineEndingsCRLF
+	case e.cr && !e.lf && !e.crlf:
+		return LineEndingsCR
+	case e.lf || e.crlf || e.cr:
+		return LineEndingsMixed
+	}
+	return LineEndingsNone
+}
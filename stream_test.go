@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountLinesLongLines(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// padding places the next marker so that it straddles a buffer boundary
+	padding := func(n int) string { return strings.Repeat("x", n) }
+
+	tests := []struct {
+		name            string
+		content         string
+		wantComment     int
+		wantCode        int
+		wantMixed       int
+		wantLineEndings string
+	}{
+		{
+			name:        "Minified bundle",
+			content:     "var a=1;" + strings.Repeat("function f(){return 1};", 200000) + "\n// end\n",
+			wantComment: 1,
+			wantCode:    1,
+		},
+		{
+			name:     "Comment marker inside a long string",
+			content:  `var s = "` + padding(3*1024*1024) + `// not a comment";` + "\n",
+			wantCode: 1,
+		},
+		{
+			name:        "Block comment opening across a buffer boundary",
+			content:     "var a = " + padding(lineHeadSize-9) + "/* open\nstill comment */\n",
+			wantComment: 1,
+			wantCode:    1,
+			wantMixed:   1,
+		},
+		{
+			name:        "Line comment after several buffers",
+			content:     padding(5*lineHeadSize+3) + " // trailing\n/* a */\n",
+			wantComment: 1,
+			wantCode:    1,
+			wantMixed:   1,
+		},
+		{
+			name:            "CRLF across a buffer boundary",
+			content:         "x" + padding(lineHeadSize-2) + "\r\n// c\r\n",
+			wantComment:     1,
+			wantCode:        1,
+			wantLineEndings: LineEndingsCRLF,
+		},
+		{
+			name:     "Unterminated long final line",
+			content:  padding(2*lineHeadSize + 5),
+			wantCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, "bundle.js")
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, Languages[".js"])
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			if stats.CommentLines != tt.wantComment {
+				t.Errorf("CommentLines = %d, want %d", stats.CommentLines, tt.wantComment)
+			}
+			if stats.CodeLines != tt.wantCode {
+				t.Errorf("CodeLines = %d, want %d", stats.CodeLines, tt.wantCode)
+			}
+			if stats.MixedLines != tt.wantMixed {
+				t.Errorf("MixedLines = %d, want %d", stats.MixedLines, tt.wantMixed)
+			}
+			if tt.wantLineEndings != "" && stats.LineEndings != tt.wantLineEndings {
+				t.Errorf("LineEndings = %q, want %q", stats.LineEndings, tt.wantLineEndings)
+			}
+			if len(stats.Diagnostics) != 0 {
+				t.Errorf("Diagnostics = %v, want none", stats.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestCountLinesGenericLongLine(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	filePath := filepath.Join(tmpDir, "fixture.dat")
+	content := strings.Repeat("a", 2*1024*1024) + "\n\n" + strings.Repeat(" ", 3*lineHeadSize) + "\n"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	stats, err := CountLinesGeneric(filePath)
+	if err != nil {
+		t.Fatalf("CountLinesGeneric failed: %v", err)
+	}
+	if stats.CodeLines != 1 || stats.BlankLines != 2 || stats.TotalLines != 3 {
+		t.Errorf("Code, Blank, Total = %d, %d, %d, want 1, 2, 3", stats.CodeLines, stats.BlankLines, stats.TotalLines)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import "sort"
+
+// FNV-1a parameters, used to hash lines without allocating
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// hashBytes adds the bytes of s to an FNV-1a hash
+func hashBytes[T string | []byte](h uint64, s T) uint64 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime
+	}
+	return h
+}
+
+// hashUint64 adds the bytes of v to an FNV-1a hash
+func hashUint64(h, v uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h ^= v & 0xff
+		h *= fnvPrime
+		v >>= 8
+	}
+	return h
+}
+
+// hashSink passes lines through to a counter while hashing each of them with
+// the surrounding whitespace trimmed. Code lines are recorded for the ULOC
+// count of whichever statistics counted them, and the hashes of all lines
+// make up a hash of the file's content that ignores line endings and
+// indentation.
+type hashSink struct {
+	lineSink
+	stats *FileStats
+
+	fileHash uint64
+
+	// State of the current line
+	lineHash uint64
+	started  bool   // a non-whitespace byte has been hashed
+	space    []byte // whitespace hashed only if more text follows
+	before   []int  // code lines of stats and its embedded regions
+}
+
+// newHashSink creates a hashSink recording into stats
+func newHashSink(sink lineSink, stats *FileStats) *hashSink {
+	return &hashSink{lineSink: sink, stats: stats, fileHash: fnvOffset}
+}
+
+func (h *hashSink) beginLine(head string) {
+	h.lineHash = fnvOffset
+	h.started = false
+	h.space = h.space[:0]
+
+	h.before = append(h.before[:0], h.stats.CodeLines)
+	for _, child := range h.stats.Embedded {
+		h.before = append(h.before, child.CodeLines)
+	}
+	h.lineSink.beginLine(head)
+}
+
+func (h *hashSink) scan(text string, start int, final bool) int {
+	stop := h.lineSink.scan(text, start, final)
+	h.hashText(text[start:min(stop, len(text))]) // an escape may skip past the end
+	return stop
+}
+
+// hashText adds text to the line hash, leaving out leading whitespace and
+// holding back whitespace until something follows it
+func (h *hashSink) hashText(text string) {
+	for len(text) > 0 {
+		i := 0
+		for i < len(text) && !isWhitespace(text[i]) {
+			i++
+		}
+		if i > 0 {
+			h.lineHash = hashBytes(h.lineHash, h.space)
+			h.lineHash = hashBytes(h.lineHash, text[:i])
+			h.space = h.space[:0]
+			h.started = true
+		}
+
+		j := i
+		for j < len(text) && isWhitespace(text[j]) {
+			j++
+		}
+		if h.started {
+			h.space = append(h.space, text[i:j]...)
+		}
+		text = text[j:]
+	}
+}
+
+func (h *hashSink) endLine() {
+	h.lineSink.endLine()
+
+	// The line belongs to whichever statistics counted it as code
+	if h.stats.CodeLines > h.before[0] {
+		h.stats.addUniqueLine(h.lineHash)
+	}
+	for i, child := range h.stats.Embedded {
+		before := 0
+		if i+1 < len(h.before) {
+			before = h.before[i+1]
+		}
+		if child.CodeLines > before {
+			child.addUniqueLine(h.lineHash)
+		}
+	}
+
+	h.fileHash = hashUint64(h.fileHash, h.lineHash)
+}
+
+func (h *hashSink) finish() {
+	h.lineSink.finish()
+	h.stats.ContentHash = h.fileHash
+}
+
+// addUniqueLine records the hash of a code line
+func (fs *FileStats) addUniqueLine(hash uint64) {
+	if fs.lineHashes == nil {
+		fs.lineHashes = make(map[uint64]struct{})
+	}
+	fs.lineHashes[hash] = struct{}{}
+	fs.UniqueLines = len(fs.lineHashes)
+}
+
+// ReleaseLineHashes frees the code line hashes of files and their embedded
+// regions, which are only needed until their statistics are aggregated
+func ReleaseLineHashes(fileStats []*FileStats) {
+	for _, fs := range fileStats {
+		if fs == nil {
+			continue
+		}
+		fs.lineHashes = nil
+		for _, child := range fs.Embedded {
+			child.lineHashes = nil
+		}
+	}
+}
+
+// addUniqueLines merges the code line hashes of a file or language into the
+// statistics of a language
+func (ls *LanguageStats) addUniqueLines(hashes map[uint64]struct{}) {
+	if len(hashes) == 0 {
+		return
+	}
+	if ls.lineHashes == nil {
+		ls.lineHashes = make(map[uint64]struct{}, len(hashes))
+	}
+	for hash := range hashes {
+		ls.lineHashes[hash] = struct{}{}
+	}
+	ls.UniqueLines = len(ls.lineHashes)
+}
+
+// DuplicateGroup lists files whose contents are the same once line endings
+// and the whitespace around each line are ignored
+type DuplicateGroup struct {
+	Files []*FileStats
+}
+
+// FindDuplicates groups files with identical contents, largest groups first.
+// Files without code or comments, such as empty __init__.py files, are left
+// out.
+func FindDuplicates(fileStats []*FileStats) []DuplicateGroup {
+	byHash := make(map[uint64][]*FileStats)
+	for _, fs := range fileStats {
+		if fs == nil || fs.CodeLines+fs.CommentLines == 0 {
+			continue
+		}
+		byHash[fs.ContentHash] = append(byHash[fs.ContentHash], fs)
+	}
+
+	var groups []DuplicateGroup
+	for _, files := range byHash {
+		if len(files) < 2 {
+			continue
+		}
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].FilePath < files[j].FilePath
+		})
+		groups = append(groups, DuplicateGroup{Files: files})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i].Files) != len(groups[j].Files) {
+			return len(groups[i].Files) > len(groups[j].Files)
+		}
+		return groups[i].Files[0].FilePath < groups[j].Files[0].FilePath
+	})
+	return groups
+}
+
+// Deduplicate returns the files with all but the first of each group of
+// duplicates removed, so that copies are only counted once
+func Deduplicate(fileStats []*FileStats, groups []DuplicateGroup) []*FileStats {
+	copies := make(map[*FileStats]bool)
+	for _, group := range groups {
+		for _, fs := range group.Files[1:] {
+			copies[fs] = true
+		}
+	}
+
+	kept := make([]*FileStats, 0, len(fileStats))
+	for _, fs := range fileStats {
+		if !copies[fs] {
+			kept = append(kept, fs)
+		}
+	}
+	return kept
+}
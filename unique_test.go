@@ -0,0 +1,189 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCountLinesUniqueLines(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name         string
+		filename     string
+		content      string
+		wantCode     int
+		wantUnique   int
+		wantEmbedded int
+	}{
+		{
+			name:       "Repeated lines",
+			filename:   "main.go",
+			content:    "package main\n\nfunc a() {\n}\n\nfunc b() {\n}\n",
+			wantCode:   5,
+			wantUnique: 4,
+		},
+		{
+			name:       "Whitespace around lines is ignored",
+			filename:   "space.go",
+			content:    "x := 1\n\t x := 1  \n x  :=  1\n",
+			wantCode:   3,
+			wantUnique: 2,
+		},
+		{
+			name:       "Comments are not code",
+			filename:   "comment.go",
+			content:    "// x := 1\nx := 1\n",
+			wantCode:   1,
+			wantUnique: 1,
+		},
+		{
+			name:         "Embedded regions",
+			filename:     "App.vue",
+			content:      "<template>\n  <div/>\n</template>\n<script>\nlet a = 1\nlet a = 1\n</script>\n",
+			wantCode:     5,
+			wantUnique:   5,
+			wantEmbedded: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tmpDir, tt.filename)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			stats, err := CountLines(filePath, Languages[filepath.Ext(tt.filename)])
+			if err != nil {
+				t.Fatalf("CountLines failed: %v", err)
+			}
+
+			if stats.CodeLines != tt.wantCode {
+				t.Errorf("CodeLines = %d, want %d", stats.CodeLines, tt.wantCode)
+			}
+			if stats.UniqueLines != tt.wantUnique {
+				t.Errorf("UniqueLines = %d, want %d", stats.UniqueLines, tt.wantUnique)
+			}
+			embedded := 0
+			for _, child := range stats.Embedded {
+				embedded += child.UniqueLines
+			}
+			if embedded != tt.wantEmbedded {
+				t.Errorf("embedded UniqueLines = %d, want %d", embedded, tt.wantEmbedded)
+			}
+		})
+	}
+}
+
+func TestAggregateUniqueLines(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"a.go": "package main\nvar a = 1\n",
+		"b.go": "package main\nvar b = 2\n",
+		"c.py": "a = 1\n",
+	}
+	var fileStats []*FileStats
+	for name, content := range files {
+		filePath := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		stats, err := CountLines(filePath, Languages[filepath.Ext(name)])
+		if err != nil {
+			t.Fatalf("CountLines failed: %v", err)
+		}
+		fileStats = append(fileStats, stats)
+	}
+
+	langStats := AggregateStats(fileStats)
+	if got := langStats["Go"].UniqueLines; got != 3 {
+		t.Errorf("Go UniqueLines = %d, want 3", got)
+	}
+	if got := TotalStats(langStats).UniqueLines; got != 4 {
+		t.Errorf("Total UniqueLines = %d, want 4", got)
+	}
+	if got := AggregateStats(fileStats)["Go"].UniqueLines; got != 3 {
+		t.Errorf("Go UniqueLines aggregated again = %d, want 3", got)
+	}
+
+	ReleaseLineHashes(fileStats)
+	for _, fs := range fileStats {
+		if fs.lineHashes != nil {
+			t.Errorf("%s line hashes kept after release", fs.FilePath)
+		}
+		if fs.UniqueLines == 0 {
+			t.Errorf("%s UniqueLines reset by release", fs.FilePath)
+		}
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "locc-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"vendor/util.go", "package util\n\nfunc F() {\n\treturn\n}\n"},
+		{"util.go", "package util\r\n\r\nfunc F() {\r\n    return   \r\n}\r\n"},
+		{"other.go", "package util\n\nfunc G() {\n\treturn\n}\n"},
+		{"reordered.go", "package util\n\nfunc F() {\n}\n\treturn\n"},
+		{"a/__init__.py", ""},
+		{"b/__init__.py", ""},
+	}
+
+	var fileStats []*FileStats
+	for _, f := range files {
+		filePath := filepath.Join(tmpDir, f.name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(filePath, []byte(f.content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		stats, err := CountLines(filePath, Languages[filepath.Ext(f.name)])
+		if err != nil {
+			t.Fatalf("CountLines failed: %v", err)
+		}
+		fileStats = append(fileStats, stats)
+	}
+
+	groups := FindDuplicates(fileStats)
+	if len(groups) != 1 {
+		t.Fatalf("FindDuplicates() found %d groups, want 1", len(groups))
+	}
+	var got []string
+	for _, fs := range groups[0].Files {
+		got = append(got, filepath.Base(filepath.Dir(fs.FilePath))+"/"+filepath.Base(fs.FilePath))
+	}
+	want := []string{filepath.Base(tmpDir) + "/util.go", "vendor/util.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("duplicate group = %v, want %v", got, want)
+	}
+
+	kept := Deduplicate(fileStats, groups)
+	if len(kept) != len(fileStats)-1 {
+		t.Errorf("Deduplicate() kept %d files, want %d", len(kept), len(fileStats)-1)
+	}
+	for _, fs := range kept {
+		if fs == groups[0].Files[1] {
+			t.Errorf("Deduplicate() kept the copy %s", fs.FilePath)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -22,11 +24,13 @@ type Walker struct {
 	excludeDirs     map[string]bool
 	excludePatterns []string
 	includeHidden   bool
+	countOptions    CountOptions
+	detectOptions   DetectOptions
 	results         []*FileStats
 	errors          []error
 	mu              sync.Mutex
 	processedFiles  int
-	skippedFiles    int
+	skipped         []SkippedFile
 }
 
 // NewWalker creates a new Walker instance
@@ -91,6 +95,16 @@ func (w *Walker) SetIncludeHidden(include bool) {
 	w.includeHidden = include
 }
 
+// SetCountOptions sets the counting modes used for every file
+func (w *Walker) SetCountOptions(opts CountOptions) {
+	w.countOptions = opts
+}
+
+// SetDetectOptions sets how ambiguous file languages are resolved
+func (w *Walker) SetDetectOptions(opts DetectOptions) {
+	w.detectOptions = opts
+}
+
 // Walk traverses the directory tree and processes files concurrently
 func (w *Walker) Walk() ([]*FileStats, []error) {
 	jobs := make(chan FileJob, 1000)
@@ -154,9 +168,7 @@ func (w *Walker) Walk() ([]*FileStats, []error) {
 			match, err := filepath.Match(pattern, fileName)
 			if err == nil && match {
 				LogDebug("Skipping file matching pattern %s: %s", pattern, path)
-				w.mu.Lock()
-				w.skippedFiles++
-				w.mu.Unlock()
+				w.recordSkip(path, SkipExcludedPattern)
 				return nil
 			}
 		}
@@ -164,9 +176,7 @@ func (w *Walker) Walk() ([]*FileStats, []error) {
 		// Skip binary files first
 		if IsBinaryExtension(ext) {
 			LogDebug("Skipping binary file: %s", path)
-			w.mu.Lock()
-			w.skippedFiles++
-			w.mu.Unlock()
+			w.recordSkip(path, SkipBinaryExtension)
 			return nil
 		}
 
@@ -186,39 +196,16 @@ func (w *Walker) Walk() ([]*FileStats, []error) {
 			// Unknown hidden file, skip unless includeHidden is set
 			if !w.includeHidden {
 				LogDebug("Skipping unknown hidden file: %s", path)
-				w.mu.Lock()
-				w.skippedFiles++
-				w.mu.Unlock()
+				w.recordSkip(path, SkipHidden)
 				return nil
 			}
 		}
 
-		// Try to get language by extension first
-		lang := GetLanguage(ext)
-		if lang == nil {
-			// Try case-sensitive lookup for extensions like .R
-			lang = GetLanguage(filepath.Ext(path))
-		}
-
-		// If no language found by extension, try by filename
-		if lang == nil {
-			lang = GetLanguageByFilename(fileName)
-		}
-
-		// If still no language found, skip the file
-		if lang == nil {
-			LogDebug("Skipping unsupported file: %s", path)
-			w.mu.Lock()
-			w.skippedFiles++
-			w.mu.Unlock()
-			return nil
-		}
-
-		// Send job to workers
+		// Send job to workers, which resolve the language and check the
+		// content for binary data from a single read of the file's head
 		jobs <- FileJob{
 			Path:      path,
 			Extension: ext,
-			Language:  lang,
 		}
 
 		return nil
@@ -246,7 +233,12 @@ func (w *Walker) worker(jobs <-chan FileJob, results chan<- CountResult, wg *syn
 	defer wg.Done()
 
 	for job := range jobs {
-		stats, err := CountLines(job.Path, job.Language)
+		stats, reason, err := countFile(job.Path, job.Language, w.detectOptions, w.countOptions)
+		if reason != "" {
+			LogDebug("Skipping file (%s): %s", reason, job.Path)
+			w.recordSkip(job.Path, reason)
+			continue
+		}
 		if stats != nil {
 			stats.Extension = job.Extension
 		}
@@ -257,6 +249,44 @@ func (w *Walker) worker(jobs <-chan FileJob, results chan<- CountResult, wg *syn
 	}
 }
 
+// countFile counts the lines of a file, reading its head once to detect its
+// language, unless lang is already known, and to check it for binary
+// content. It returns the reason the file was skipped, if it was.
+func countFile(path string, lang *Language, detectOpts DetectOptions, countOpts CountOptions) (*FileStats, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, max(binaryProbeSize, maxHeuristicLength))
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, "", err
+	}
+	head = head[:n]
+
+	// Resolve the language by extension, content, filename or shebang
+	if lang == nil {
+		lang = detectLanguage(path, head, detectOpts)
+	}
+	if lang == nil {
+		return nil, SkipUnsupported, nil
+	}
+
+	// Extensions only catch known binary formats, so check the content too
+	if reason := sniffBinary(head[:min(n, binaryProbeSize)]); reason != "" {
+		return nil, reason, nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, "", err
+	}
+	stats, err := countReader(path, io.MultiReader(bytes.NewReader(head), file), info.Size(), lang, countOpts)
+	return stats, "", err
+}
+
 // collectResults collects results from the results channel
 func (w *Walker) collectResults(results <-chan CountResult, wg *sync.WaitGroup) {
 	defer wg.Done()
@@ -284,7 +314,21 @@ func (w *Walker) GetProcessedCount() int {
 func (w *Walker) GetSkippedCount() int {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	return w.skippedFiles
+	return len(w.skipped)
+}
+
+// GetSkippedFiles returns the skipped files with the reason for each
+func (w *Walker) GetSkippedFiles() []SkippedFile {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.skipped
+}
+
+// recordSkip records a file that will not be counted
+func (w *Walker) recordSkip(path, reason string) {
+	w.mu.Lock()
+	w.skipped = append(w.skipped, SkippedFile{Path: path, Reason: reason})
+	w.mu.Unlock()
 }
 
 // GetErrorCount returns the number of errors encountered
@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -216,6 +217,83 @@ func TestWalkerSkipsBinaryFiles(t *testing.T) {
 	}
 }
 
+func TestWalkerSkipsBinaryContent(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "walker-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Binary data behind a source extension
+	if err := os.WriteFile(filepath.Join(tmpDir, "fixture.go"), []byte("\x7fELF\x02\x01\x00\x00\x00"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	walker := NewWalker(tmpDir, 2)
+	stats, _ := walker.Walk()
+
+	if len(stats) != 1 {
+		t.Errorf("Expected 1 file (excluding binary content), got %d", len(stats))
+	}
+	skipped := walker.GetSkippedFiles()
+	if len(skipped) != 1 || skipped[0].Reason != SkipBinaryNUL {
+		t.Errorf("GetSkippedFiles() = %v, want one file skipped for %q", skipped, SkipBinaryNUL)
+	}
+}
+
+func TestCountFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "walker-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Content past the head read for detection is still counted
+	long := "#!/usr/bin/env python3\n" + strings.Repeat("x = 1\n", 3000)
+	files := map[string]string{
+		"script":   long,
+		"data.go":  "\x7fELF\x02\x01\x00\x00\x00",
+		"notes.zz": "plain text\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		wantLang   string
+		wantCode   int
+		wantReason string
+	}{
+		{"script", "Python", 3001, ""},
+		{"data.go", "", 0, SkipBinaryNUL},
+		{"notes.zz", "", 0, SkipUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, reason, err := countFile(filepath.Join(tmpDir, tt.name), nil, DetectOptions{}, CountOptions{})
+			if err != nil {
+				t.Fatalf("countFile() error = %v", err)
+			}
+			if reason != tt.wantReason {
+				t.Errorf("countFile() reason = %q, want %q", reason, tt.wantReason)
+			}
+			if tt.wantReason != "" {
+				return
+			}
+			if stats.Language != tt.wantLang || stats.CodeLines != tt.wantCode || stats.Bytes != len(long) {
+				t.Errorf("countFile() = %s with %d code lines and %d bytes, want %s with %d and %d",
+					stats.Language, stats.CodeLines, stats.Bytes, tt.wantLang, tt.wantCode, len(long))
+			}
+		})
+	}
+}
+
 func TestWalkerSkipsUnsupportedExtensions(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "walker-test")
 	if err != nil {
@@ -241,6 +319,36 @@ func TestWalkerSkipsUnsupportedExtensions(t *testing.T) {
 	}
 }
 
+func TestWalkerDetectsShebangScripts(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "walker-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	binDir := filepath.Join(tmpDir, "bin")
+	if err := os.Mkdir(binDir, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	script := "#!/usr/bin/env python3\n# comment\nprint('hi')\n"
+	if err := os.WriteFile(filepath.Join(binDir, "deploy"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	walker := NewWalker(tmpDir, 2)
+	stats, _ := walker.Walk()
+
+	if len(stats) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(stats))
+	}
+	if stats[0].Language != "Python" {
+		t.Errorf("Language = %q, want %q", stats[0].Language, "Python")
+	}
+	if stats[0].CodeLines != 2 || stats[0].CommentLines != 1 {
+		t.Errorf("CodeLines = %d, CommentLines = %d, want 2 and 1", stats[0].CodeLines, stats[0].CommentLines)
+	}
+}
+
 func TestWalkerNonexistentPath(t *testing.T) {
 	walker := NewWalker("/nonexistent/path", 2)
 	_, errors := walker.Walk()